@@ -4,6 +4,7 @@ import (
 	"github.com/azeroth-sha/simple/internal"
 	"hash/fnv"
 	"runtime"
+	"sync"
 	"time"
 )
 
@@ -11,6 +12,7 @@ import (
 type Dict struct {
 	bucket      []*shard
 	closed      chan struct{}
+	closeOnce   *sync.Once
 	shardNum    uint32
 	expHandler  ExpiredHandler
 	chkInterval time.Duration
@@ -122,11 +124,19 @@ func (d *Dict) CheckAll() {
 	}
 }
 
+// Close 停止过期检测，已有数据仍可访问
+func (d *Dict) Close() {
+	d.closeOnce.Do(func() {
+		close(d.closed)
+	})
+}
+
 // New 创建一个字典
 func New(opts ...DictOption) *Dict {
 	d := &Dict{
 		bucket:      nil,
 		closed:      make(chan struct{}),
+		closeOnce:   new(sync.Once),
 		shardNum:    uint32(runtime.NumCPU() * 8),
 		expHandler:  nil,
 		chkInterval: time.Second,
@@ -154,7 +164,7 @@ func New(opts ...DictOption) *Dict {
 		}
 	}()
 	runtime.SetFinalizer(d, func(d *Dict) {
-		close(d.closed)
+		d.Close()
 	})
 	return d
 }
@@ -0,0 +1,187 @@
+package grace
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple/cache"
+	"github.com/azeroth-sha/simple/studio"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// HTTPServer 将 *http.Server 适配为 ContextServer
+// 指定监听器（如 Grace.Listen 的返回值）时 Start 调用 Serve，否则调用 ListenAndServe；
+// Stop 调用 Shutdown，在停止期限内等待已有连接处理完毕
+func HTTPServer(srv *http.Server, l ...net.Listener) ContextServer {
+	h := &httpServer{srv: srv}
+	if len(l) > 0 {
+		h.l = l[0]
+	}
+	return h
+}
+
+// ListenerServer 将 net.Listener 的接收循环适配为 ContextServer
+// 每个连接在独立的协程中交给 handle 处理，handle 返回后连接被关闭；
+// handle 的 ctx 在服务停止时被取消，Stop 在停止期限内等待所有连接处理完毕
+func ListenerServer(l net.Listener, handle func(ctx context.Context, conn net.Conn)) ContextServer {
+	return &listenerServer{
+		l:      l,
+		handle: handle,
+		wait:   new(sync.WaitGroup),
+	}
+}
+
+// TickerServer 将周期任务适配为 ContextServer
+// 每隔 d 时长执行一次 job，上一次执行结束前不会开始下一次；job 的 ctx 在服务停止时被取消
+// d 小于等于 0 时 panic
+func TickerServer(d time.Duration, job func(ctx context.Context)) ContextServer {
+	if d <= 0 {
+		panic("grace: non-positive interval for TickerServer")
+	}
+	return &tickerServer{
+		d:   d,
+		job: job,
+	}
+}
+
+// DictServer 将 cache.Dict 适配为 Server
+// Start 阻塞直到停止；Stop 执行一次过期检测并关闭字典的后台检测
+func DictServer(d *cache.Dict) Server {
+	return &dictServer{
+		d:      d,
+		closed: make(chan struct{}),
+		once:   new(sync.Once),
+	}
+}
+
+// StudioServer 将 studio.Studio 适配为 ContextServer
+// Stop 在停止期限内处理完队列中的剩余事件，期限到达后未处理的事件通过 *studio.ReleaseError 返回
+func StudioServer(s studio.Studio) ContextServer {
+	return &studioServer{s: s}
+}
+
+/*
+  内部方法
+*/
+
+// httpServer 是 *http.Server 的适配器
+type httpServer struct {
+	srv *http.Server
+	l   net.Listener
+}
+
+func (h *httpServer) Start(context.Context) error {
+	var err error
+	if h.l != nil {
+		err = h.srv.Serve(h.l)
+	} else {
+		err = h.srv.ListenAndServe()
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+func (h *httpServer) Stop(ctx context.Context) error {
+	return h.srv.Shutdown(ctx)
+}
+
+// listenerServer 是 net.Listener 接收循环的适配器
+type listenerServer struct {
+	l      net.Listener
+	handle func(ctx context.Context, conn net.Conn)
+	wait   *sync.WaitGroup
+}
+
+func (s *listenerServer) Start(ctx context.Context) error {
+	for {
+		conn, err := s.l.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		s.wait.Add(1)
+		go func() {
+			defer s.wait.Done()
+			defer func() { _ = conn.Close() }()
+			s.handle(ctx, conn)
+		}()
+	}
+}
+
+func (s *listenerServer) Stop(ctx context.Context) error {
+	err := s.l.Close()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		s.wait.Wait()
+	}()
+	select {
+	case <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// tickerServer 是周期任务的适配器
+type tickerServer struct {
+	d   time.Duration
+	job func(ctx context.Context)
+}
+
+func (t *tickerServer) Start(ctx context.Context) error {
+	tk := time.NewTicker(t.d)
+	defer tk.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-tk.C:
+			t.job(ctx)
+		}
+	}
+}
+
+func (t *tickerServer) Stop(context.Context) error {
+	return nil // Start 的 ctx 被取消后，当前任务结束即退出
+}
+
+// dictServer 是 cache.Dict 的适配器
+type dictServer struct {
+	d      *cache.Dict
+	closed chan struct{}
+	once   *sync.Once
+}
+
+func (s *dictServer) Start() error {
+	<-s.closed
+	return nil
+}
+
+func (s *dictServer) Stop() error {
+	s.once.Do(func() {
+		s.d.CheckAll()
+		s.d.Close()
+		close(s.closed)
+	})
+	return nil
+}
+
+// studioServer 是 studio.Studio 的适配器
+type studioServer struct {
+	s studio.Studio
+}
+
+func (s *studioServer) Start(context.Context) error {
+	return s.s.Start()
+}
+
+func (s *studioServer) Stop(ctx context.Context) error {
+	return s.s.Drain(ctx)
+}
@@ -0,0 +1,16 @@
+package grace_test
+
+import (
+	"context"
+	"github.com/azeroth-sha/simple/grace"
+	"testing"
+)
+
+func TestTickerServerInterval(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("TickerServer(0) did not panic")
+		}
+	}()
+	grace.TickerServer(0, func(context.Context) {})
+}
@@ -0,0 +1,133 @@
+package grace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/azeroth-sha/simple/studio"
+	"time"
+)
+
+// EventKind 表示生命周期事件的类型
+type EventKind uint8
+
+const (
+	EventServiceStarting    EventKind = iota // 服务开始启动
+	EventServiceStarted                      // 服务已就绪
+	EventServiceCrashed                      // 服务的 Start 返回错误或发生恐慌
+	EventServiceRestarting                   // 服务等待重新启动
+	EventServiceFailed                       // 服务重试次数用尽，不再重新启动
+	EventServiceStopped                      // 服务已退出
+	EventServiceStopTimeout                  // 服务未能在 StopTimeout 或整体停止期限内停止
+	EventGraceStopping                       // Grace 开始停止所有服务
+	EventGraceStopped                        // Grace 已停止所有服务
+)
+
+// String 返回事件类型名称，同时作为发布到 studio.Studio 的事件名称
+func (k EventKind) String() string {
+	switch k {
+	case EventServiceStarting:
+		return "service.starting"
+	case EventServiceStarted:
+		return "service.started"
+	case EventServiceCrashed:
+		return "service.crashed"
+	case EventServiceRestarting:
+		return "service.restarting"
+	case EventServiceFailed:
+		return "service.failed"
+	case EventServiceStopped:
+		return "service.stopped"
+	case EventServiceStopTimeout:
+		return "service.stop_timeout"
+	case EventGraceStopping:
+		return "grace.stopping"
+	case EventGraceStopped:
+		return "grace.stopped"
+	default:
+		return fmt.Sprintf("event(%d)", uint8(k))
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (k EventKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// Event 表示一个生命周期事件
+type Event struct {
+	Kind    EventKind     // 事件类型
+	Service string        // 服务名称，Grace 级别的事件为空
+	Time    time.Time     // 事件发生的时间
+	Err     error         // 服务崩溃、失败或停止时的错误
+	Panic   any           // 服务恐慌时的恐慌值
+	Stack   []byte        // 服务恐慌时的调用栈
+	Backoff time.Duration // 服务重新启动前的等待时长
+}
+
+// PanicError 表示服务的 Start 发生恐慌
+type PanicError struct {
+	Value any    // 恐慌值
+	Stack []byte // 恐慌时的调用栈
+}
+
+// Error 实现 error 接口
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Subscribe 订阅生命周期事件，size 为通道的缓冲大小
+// 事件发送不阻塞，缓冲已满时丢弃该订阅者的事件；调用返回的函数取消订阅并关闭通道
+func (g *Grace) Subscribe(size int) (<-chan Event, func()) {
+	if size < 0 {
+		size = 0
+	}
+	ch := make(chan Event, size)
+	g.subMu.Lock()
+	g.subs[ch] = struct{}{}
+	g.subMu.Unlock()
+	return ch, func() {
+		g.subMu.Lock()
+		defer g.subMu.Unlock()
+		if _, ok := g.subs[ch]; ok {
+			delete(g.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+/*
+  内部方法
+*/
+
+// emit 将事件发送给所有订阅者，设置了 WithStudio 时同时发布到 studio.Studio
+func (g *Grace) emit(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	g.subMu.RLock()
+	for ch := range g.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+	g.subMu.RUnlock()
+	if g.studio != nil {
+		_ = g.studio.Task(studio.NewEvent(ev.Kind.String(), ev, nil, ev.Time), false)
+	}
+}
+
+// notify 发送服务的生命周期事件
+func (s *Service) notify(kind EventKind, err error, backoff time.Duration) {
+	ev := Event{
+		Kind:    kind,
+		Service: s.name,
+		Err:     err,
+		Backoff: backoff,
+	}
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		ev.Panic, ev.Stack = pe.Value, pe.Stack
+	}
+	s.emit(ev)
+}
@@ -1,80 +1,166 @@
 package grace
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"github.com/azeroth-sha/simple"
-	"sort"
+	"github.com/azeroth-sha/simple/studio"
+	"os"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrStopTimeout 表示服务未能在期限内停止
+var ErrStopTimeout = errors.New(`grace: stop timeout`)
+
+// StopError 列出未能在期限内停止的服务
+type StopError struct {
+	Services []string // 未能按时停止的服务名称，按停止顺序排列
+}
+
+// Error 实现 error 接口
+func (e *StopError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrStopTimeout, strings.Join(e.Services, ", "))
+}
+
+// Unwrap 返回 ErrStopTimeout，便于使用 errors.Is 判断
+func (e *StopError) Unwrap() error {
+	return ErrStopTimeout
+}
+
 // Grace 优雅关闭服务
 type Grace struct {
-	mu       *sync.Mutex
-	wait     *sync.WaitGroup
-	srv      []*service
-	logger   simple.Logger
-	interval time.Duration
+	mu        *sync.Mutex
+	running   int32
+	wait      *sync.WaitGroup
+	srv       []*Service
+	logger    simple.Logger
+	interval  time.Duration
+	timeout   time.Duration
+	reload    func() error
+	ready     time.Duration
+	down      chan struct{}
+	downOnce  *sync.Once
+	err       error
+	listeners []*listener
+	inherits  []*os.File
+	released  map[string]struct{} // Run 后关闭的继承监听器
+	parent    int
+	subMu     *sync.RWMutex
+	subs      map[chan Event]struct{}
+	studio    studio.Studio
+}
+
+// Add 添加服务，返回的服务可通过 DependsOn 声明依赖
+// Run 之后添加的服务不会自动启动，需调用 StartService 启动
+func (g *Grace) Add(name string, server Server, priority ...int) *Service {
+	return g.add(name, server, priority...)
+}
+
+// AddContext 添加支持上下文的服务，返回的服务可通过 DependsOn 声明依赖
+// Run 之后添加的服务不会自动启动，需调用 StartService 启动
+func (g *Grace) AddContext(name string, server ContextServer, priority ...int) *Service {
+	return g.add(name, server, priority...)
 }
 
-// Add 添加服务
-func (g *Grace) Add(name string, server Server, priority ...int) {
+// add 添加 Server 或 ContextServer 服务
+func (g *Grace) add(name string, server any, priority ...int) *Service {
 	g.mu.Lock()
 	defer g.mu.Unlock()
-	g.srv = append(g.srv, newServ(name, server, g.logger, g.wait, priority...))
+	srv := newServ(name, server, g.logger, g.wait, priority...)
+	srv.onFail = g.fail
+	srv.emit = g.emit
+	g.srv = append(g.srv, srv)
+	return srv
 }
 
-// Run 启动服务
-func (g *Grace) Run() {
-	all := make([]*service, 0, len(g.srv))
-	all = append(all, g.srv...)
-	sort.SliceStable(all, func(i, j int) bool {
-		if all[i].priority != all[j].priority {
-			return all[i].priority < all[j].priority
-		} else {
-			return all[i].name < all[j].name
-		}
-	})
+// Run 按依赖的拓扑顺序启动服务，依赖相同时按优先级和名称排序
+// 存在未知依赖、重复名称或循环依赖时不启动任何服务并返回错误
+// 每个服务就绪后才启动下一个服务，超过 WithReadyTimeout 设置的时长未就绪时返回错误，已启动的服务需调用 Stop 停止
+// 所有服务就绪后关闭未被 Listen 取用的继承监听器，详见 Listen
+func (g *Grace) Run() error {
+	all, err := g.sorted()
+	if err != nil {
+		return err
+	}
 	g.logger.Info("grace start")
 	for _, srv := range all {
 		srv.start()
+		if err = g.waitReady(srv); err != nil { // 等待就绪后再启动依赖该服务的服务
+			return err
+		}
 		if g.interval > 0 {
 			time.Sleep(g.interval)
 		}
 	}
 	g.logger.Info("grace running")
+	atomic.StoreInt32(&g.running, 1)
+	g.closeInherits() // 所有服务已就绪，未被取用的继承监听器不再需要，见 Listen
+	g.notifyParent()  // 热升级启动的子进程就绪后通知父进程退出
+	return nil
 }
 
-// Stop 停止服务
-func (g *Grace) Stop() {
-	all := make([]*service, 0, len(g.srv))
-	all = append(all, g.srv...)
-	sort.SliceStable(all, func(i, j int) bool {
-		if all[i].priority != all[j].priority {
-			return all[i].priority > all[j].priority
-		} else {
-			return all[i].name > all[j].name
-		}
-	})
+// Stop 按启动顺序的逆序停止服务
+// 设置了 WithShutdownTimeout 时整体停止时间不超过该时长，未能按时停止的服务通过 *StopError 返回
+func (g *Grace) Stop() error {
+	ctx, cancel := g.stopContext()
+	defer cancel()
+	return g.Shutdown(ctx)
+}
+
+// Shutdown 按启动顺序的逆序停止服务，ctx 结束后不再等待剩余的服务
+// 每个服务的停止时间同时受 StopTimeout 限制，未能按时停止的服务通过 *StopError 返回
+func (g *Grace) Shutdown(ctx context.Context) error {
+	all, err := g.sorted()
+	if err != nil {
+		all = g.prioritized()
+	}
+	for i, j := 0, len(all)-1; i < j; i, j = i+1, j-1 {
+		all[i], all[j] = all[j], all[i]
+	}
+	atomic.StoreInt32(&g.running, 0)
 	g.logger.Info("grace stop")
 	defer g.logger.Info("grace stopped")
+	g.emit(Event{Kind: EventGraceStopping})
+	defer g.emit(Event{Kind: EventGraceStopped})
+	timeout := make([]string, 0)
 	for _, srv := range all {
-		srv.stop()
-		if g.interval > 0 {
+		if err = srv.shutdown(ctx); err != nil {
+			timeout = append(timeout, srv.name)
+			srv.notify(EventServiceStopTimeout, err, 0)
+		}
+		if g.interval > 0 && ctx.Err() == nil {
 			time.Sleep(g.interval)
 		}
 	}
+	if len(timeout) > 0 {
+		return &StopError{Services: timeout}
+	}
 	g.wait.Wait()
+	return nil
 }
 
 // New 创建一个Grace实例
 func New(l simple.Logger, opts ...Option) *Grace {
 	g := &Grace{
-		mu:       new(sync.Mutex),
-		wait:     new(sync.WaitGroup),
-		srv:      make([]*service, 0),
-		logger:   l,
-		interval: time.Millisecond * 150,
+		mu:        new(sync.Mutex),
+		wait:      new(sync.WaitGroup),
+		srv:       make([]*Service, 0),
+		logger:    l,
+		interval:  time.Millisecond * 150,
+		timeout:   0,
+		reload:    nil,
+		ready:     time.Second * 30,
+		down:      make(chan struct{}),
+		downOnce:  new(sync.Once),
+		listeners: make([]*listener, 0),
+		subMu:     new(sync.RWMutex),
+		subs:      make(map[chan Event]struct{}),
 	}
+	g.inherits, g.parent = inheritEnv()
 	for _, option := range opts {
 		option(g)
 	}
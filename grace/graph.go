@@ -0,0 +1,141 @@
+package grace
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 依赖关系错误定义
+var (
+	ErrCycle     = errors.New(`grace: dependency cycle`)       // 服务之间存在循环依赖
+	ErrUnknown   = errors.New(`grace: unknown dependency`)     // 依赖的服务不存在
+	ErrDuplicate = errors.New(`grace: duplicate service name`) // 服务名称重复
+)
+
+/*
+  内部方法
+*/
+
+// sorted 返回按依赖拓扑排序的服务列表，同一层级按优先级和名称排序
+func (g *Grace) sorted() ([]*Service, error) {
+	all := g.prioritized()
+	index := make(map[string]*Service, len(all))
+	for _, srv := range all {
+		if _, ok := index[srv.name]; ok {
+			return nil, fmt.Errorf("%w: %s", ErrDuplicate, srv.name)
+		}
+		index[srv.name] = srv
+	}
+	degree := make(map[*Service]int, len(all))
+	for _, srv := range all {
+		for _, name := range srv.dependsOn() {
+			if _, ok := index[name]; !ok {
+				return nil, fmt.Errorf("%w: %s depends on %s", ErrUnknown, srv.name, name)
+			}
+		}
+		degree[srv] = len(g.depends(srv))
+	}
+	order := make([]*Service, 0, len(all))
+	for len(order) < len(all) {
+		var next *Service
+		for _, srv := range all { // all 已按优先级和名称排序，取第一个无未满足依赖的服务
+			if degree[srv] == 0 {
+				next = srv
+				break
+			}
+		}
+		if next == nil {
+			return nil, fmt.Errorf("%w: %s", ErrCycle, g.cycle(all, degree, index))
+		}
+		degree[next] = -1
+		order = append(order, next)
+		for _, srv := range all {
+			for _, dep := range g.depends(srv) {
+				if dep == next {
+					degree[srv]--
+				}
+			}
+		}
+	}
+	return order, nil
+}
+
+// prioritized 返回按优先级和名称排序的服务列表
+func (g *Grace) prioritized() []*Service {
+	g.mu.Lock()
+	all := make([]*Service, 0, len(g.srv))
+	all = append(all, g.srv...)
+	g.mu.Unlock()
+	sort.SliceStable(all, func(i, j int) bool {
+		if all[i].priority != all[j].priority {
+			return all[i].priority < all[j].priority
+		} else {
+			return all[i].name < all[j].name
+		}
+	})
+	return all
+}
+
+// depends 返回服务直接依赖的服务列表（去重）
+func (g *Grace) depends(srv *Service) []*Service {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	names := srv.dependsOn()
+	deps := make([]*Service, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		for _, dep := range g.srv {
+			if dep.name == name {
+				deps = append(deps, dep)
+				break
+			}
+		}
+	}
+	return deps
+}
+
+// cycle 在未能排序的服务中找出一条依赖环路，形如 a -> b -> a
+func (g *Grace) cycle(all []*Service, degree map[*Service]int, index map[string]*Service) string {
+	state := make(map[*Service]int) // 0 未访问，1 访问中，2 已完成
+	path := make([]string, 0)
+	var visit func(srv *Service) string
+	visit = func(srv *Service) string {
+		state[srv] = 1
+		path = append(path, srv.name)
+		for _, name := range srv.dependsOn() {
+			dep := index[name]
+			if degree[dep] < 0 {
+				continue // 已排序的服务不在环路上
+			}
+			switch state[dep] {
+			case 1:
+				for i, n := range path {
+					if n == dep.name {
+						return strings.Join(append(path[i:], dep.name), " -> ")
+					}
+				}
+			case 0:
+				if found := visit(dep); found != "" {
+					return found
+				}
+			}
+		}
+		state[srv] = 2
+		path = path[:len(path)-1]
+		return ""
+	}
+	for _, srv := range all {
+		if degree[srv] >= 0 && state[srv] == 0 {
+			if found := visit(srv); found != "" {
+				return found
+			}
+		}
+	}
+	return ""
+}
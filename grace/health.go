@@ -0,0 +1,179 @@
+package grace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/azeroth-sha/simple/codec"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNotReady 表示服务未在限定时间内就绪
+var ErrNotReady = errors.New(`grace: service not ready`)
+
+// Readier 是可选接口，服务实现后 Grace 在 Ready 返回的通道关闭时才认为服务就绪
+type Readier interface {
+	Ready() <-chan struct{}
+}
+
+// Checker 是可选接口，服务实现后健康报告包含 Health 的检查结果
+type Checker interface {
+	Health() error
+}
+
+// State 表示服务的运行状态
+type State uint8
+
+const (
+	StateStopped    State = iota // 已停止或未启动
+	StateStarting                // 启动中，尚未就绪
+	StateRunning                 // 运行中
+	StateRestarting              // Start 返回，等待重新启动
+	StateFailed                  // 失败，不再重新启动
+)
+
+// String 返回状态名称
+func (s State) String() string {
+	switch s {
+	case StateStopped:
+		return "stopped"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateRestarting:
+		return "restarting"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("state(%d)", uint8(s))
+	}
+}
+
+// MarshalText 实现 encoding.TextMarshaler 接口
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Status 表示单个服务的状态
+type Status struct {
+	Name   string    `json:"name"`             // 服务名称
+	State  State     `json:"state"`            // 运行状态
+	Since  time.Time `json:"since"`            // 进入当前状态的时间
+	Error  string    `json:"error,omitempty"`  // 最近一次的错误
+	Health string    `json:"health,omitempty"` // 健康检查的错误
+}
+
+// Healthy 判断服务是否健康：未处于重启或失败状态且健康检查通过
+func (s Status) Healthy() bool {
+	return s.State != StateRestarting && s.State != StateFailed && s.Health == ""
+}
+
+// Report 表示所有服务的健康报告
+type Report struct {
+	Healthy  bool     `json:"healthy"`  // 所有服务均健康
+	Ready    bool     `json:"ready"`    // Run 已完成且应当运行的服务均处于运行中
+	Services []Status `json:"services"` // 各服务状态，按优先级和名称排序
+}
+
+// Status 返回服务当前的状态
+func (s *Service) Status() Status {
+	s.mu.Lock()
+	st := Status{
+		Name:  s.name,
+		State: s.state,
+		Since: s.since,
+	}
+	if s.lastErr != nil {
+		st.Error = s.lastErr.Error()
+	}
+	s.mu.Unlock()
+	if c, ok := s.impl.(Checker); ok && st.State == StateRunning {
+		if err := c.Health(); err != nil {
+			st.Health = err.Error()
+		}
+	}
+	return st
+}
+
+// Report 返回所有服务的健康报告
+func (g *Grace) Report() Report {
+	all := g.prioritized()
+	rep := Report{
+		Healthy:  true,
+		Ready:    atomic.LoadInt32(&g.running) == 1,
+		Services: make([]Status, 0, len(all)),
+	}
+	for _, srv := range all {
+		st := srv.Status()
+		rep.Healthy = rep.Healthy && st.Healthy()
+		if srv.expected() || st.State == StateFailed { // 未启动、被停止或正常结束的服务不影响就绪
+			rep.Ready = rep.Ready && st.State == StateRunning
+		}
+		rep.Services = append(rep.Services, st)
+	}
+	return rep
+}
+
+// Handler 返回提供 /healthz 与 /readyz 的 HTTP 处理器
+// 健康或就绪时响应 200，否则响应 503，响应体为 JSON 格式的健康报告
+func (g *Grace) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		rep := g.Report()
+		writeReport(w, rep, rep.Healthy)
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		rep := g.Report()
+		writeReport(w, rep, rep.Ready)
+	})
+	return mux
+}
+
+/*
+  内部方法
+*/
+
+// expected 判断服务是否应当处于运行中：已启动，且未被停止或正常结束
+func (s *Service) expected() bool {
+	return atomic.LoadInt32(&s.running) == 1
+}
+
+// waitReady 等待服务就绪，服务未就绪即失败或超过 WithReadyTimeout 设置的时长时返回 ErrNotReady
+func (g *Grace) waitReady(srv *Service) error {
+	var timeout <-chan time.Time
+	if g.ready > 0 {
+		tm := time.NewTimer(g.ready)
+		defer tm.Stop()
+		timeout = tm.C
+	}
+	select {
+	case <-srv.readyChan():
+		if err := srv.failure(); err != nil { // 服务未就绪即已失败
+			return fmt.Errorf("%w: %s: %v", ErrNotReady, srv.name, err)
+		}
+		return nil
+	case <-g.down:
+		return fmt.Errorf("%w: %s", ErrNotReady, srv.name)
+	case <-timeout:
+		g.logger.Errorf("service %s not ready after %s", srv.name, g.ready)
+		return fmt.Errorf("%w: %s", ErrNotReady, srv.name)
+	}
+}
+
+// writeReport 输出 JSON 格式的健康报告
+func writeReport(w http.ResponseWriter, rep Report, ok bool) {
+	buf, err := codec.JsonMarshal(rep)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if ok {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	_, _ = w.Write(buf)
+}
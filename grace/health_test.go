@@ -0,0 +1,69 @@
+package grace_test
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple/grace"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestHandler(t *testing.T) {
+	g := newGrace()
+	h := g.Handler()
+	check := func(path string, want int) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		if rec.Code != want {
+			t.Errorf("%s: status %d, want %d: %s", path, rec.Code, want, rec.Body)
+		}
+	}
+	never := grace.RestartPolicy{Mode: grace.RestartNever}
+	g.AddContext(`base`, blocker{})
+	g.AddContext(`job`, oneShot{}).Restart(never)
+	check(`/readyz`, http.StatusServiceUnavailable) // Run 之前未就绪
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+	waitState(t, g, `job`, grace.StateStopped)
+	check(`/healthz`, http.StatusOK)
+	check(`/readyz`, http.StatusOK) // 正常结束的任务不影响就绪
+
+	g.AddContext(`idle`, blocker{}) // 添加但未启动
+	g.AddContext(`plugin`, blocker{})
+	if err := g.StartService(`plugin`); err != nil {
+		t.Fatal(err)
+	}
+	if err := g.StopService(`plugin`); err != nil {
+		t.Fatal(err)
+	}
+	check(`/readyz`, http.StatusOK)
+
+	g.AddContext(`broken`, oneShot{err: errors.New(`boom`)}).Restart(never)
+	_ = g.StartService(`broken`)
+	waitState(t, g, `broken`, grace.StateFailed)
+	check(`/healthz`, http.StatusServiceUnavailable)
+	check(`/readyz`, http.StatusServiceUnavailable)
+
+	_ = g.Stop()
+	check(`/readyz`, http.StatusServiceUnavailable)
+}
+
+// waitState 等待服务进入指定状态
+func waitState(t *testing.T, g *grace.Grace, name string, state grace.State) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for {
+		for _, st := range g.Services() {
+			if st.Name == name && st.State == state {
+				return
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("service %s not %s", name, state)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
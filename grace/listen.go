@@ -0,0 +1,131 @@
+package grace
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrUpgrade    = errors.New(`grace: upgrade not supported`)       // 当前平台不支持热升级
+	ErrListenLate = errors.New(`grace: inherited listener released`) // 继承的监听器未在 Run 返回前取用，已被关闭
+)
+
+const (
+	envListeners = `GRACE_LISTENERS` // 继承的监听器，格式为 network:addr，以分号分隔，依次对应文件描述符 3、4、5……
+	envParent    = `GRACE_PARENT`    // 父进程的 pid，子进程就绪后通知父进程退出
+	listenFdBase = 3                 // 继承的第一个文件描述符，0~2 为标准输入输出
+)
+
+// Listen 创建由 Grace 管理的监听器
+// 进程由热升级启动且父进程传递了相同 network 与 addr 的监听器时直接继承，否则新建监听；
+// 通过 Listen 创建的监听器在热升级时自动传递给子进程；Run 在所有服务就绪后关闭未被取用的继承监听器，
+// 因此继承的监听器需在 Run 返回前取用：在 Run 之前调用 Listen，或由实现 Readier 的服务在 Start 中调用 Listen 后再就绪；
+// 未实现 Readier 的服务在调用 Start 前即视为就绪，不能依赖在 Start 中取用继承的监听器；
+// 之后再 Listen 已被关闭的继承地址时返回 ErrListenLate，而不是因父进程仍占用该地址而失败
+func (g *Grace) Listen(network, addr string) (net.Listener, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	l, err := g.inherit(network, addr)
+	if err != nil {
+		return nil, err
+	}
+	if l == nil {
+		if _, ok := g.released[network+":"+addr]; ok {
+			return nil, fmt.Errorf("%w: %s %s", ErrListenLate, network, addr)
+		}
+		if l, err = net.Listen(network, addr); err != nil {
+			return nil, err
+		}
+	} else if ul, ok := l.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(true) // 继承的套接字文件由当前进程在关闭时删除
+	}
+	g.listeners = append(g.listeners, &listener{network: network, addr: addr, l: l})
+	return l, nil
+}
+
+/*
+  内部方法
+*/
+
+// listener 表示由 Grace 管理的监听器
+type listener struct {
+	network string
+	addr    string
+	l       net.Listener
+}
+
+// file 返回监听器文件描述符的副本
+func (l *listener) file() (*os.File, error) {
+	fl, ok := l.l.(interface{ File() (*os.File, error) })
+	if !ok {
+		return nil, fmt.Errorf("%w: %s %s", ErrUpgrade, l.network, l.addr)
+	}
+	return fl.File()
+}
+
+// handover 在监听器传递给子进程后调用，关闭时不再删除子进程仍在使用的套接字文件
+func (l *listener) handover() {
+	if ul, ok := l.l.(*net.UnixListener); ok {
+		ul.SetUnlinkOnClose(false)
+	}
+}
+
+// spec 返回监听器在环境变量中的描述
+func (l *listener) spec() string {
+	return l.network + ":" + l.addr
+}
+
+// inherit 取出父进程传递的监听器，不存在时返回 nil
+func (g *Grace) inherit(network, addr string) (net.Listener, error) {
+	for i, f := range g.inherits {
+		if f.Name() != network+":"+addr {
+			continue
+		}
+		g.inherits = append(g.inherits[:i], g.inherits[i+1:]...)
+		defer func() { _ = f.Close() }() // FileListener 复制了文件描述符
+		l, err := net.FileListener(f)
+		if err != nil {
+			return nil, err
+		}
+		g.logger.Infof("grace inherit listener %s %s", network, addr)
+		return l, nil
+	}
+	return nil, nil
+}
+
+// closeInherits 关闭父进程传递但未被 Listen 取用的监听器
+func (g *Grace) closeInherits() {
+	g.mu.Lock()
+	files := g.inherits
+	g.inherits = nil
+	for _, f := range files {
+		if g.released == nil {
+			g.released = make(map[string]struct{}, len(files))
+		}
+		g.released[f.Name()] = struct{}{}
+	}
+	g.mu.Unlock()
+	for _, f := range files {
+		g.logger.Warnf("grace close unused inherited listener %s", f.Name())
+		_ = f.Close()
+	}
+}
+
+// inheritEnv 解析父进程通过环境变量传递的监听器与 pid，解析后清除环境变量，避免传递给其他子进程
+func inheritEnv() (files []*os.File, parent int) {
+	specs := os.Getenv(envListeners)
+	parent, _ = strconv.Atoi(os.Getenv(envParent))
+	_ = os.Unsetenv(envListeners)
+	_ = os.Unsetenv(envParent)
+	if specs == "" {
+		return nil, parent
+	}
+	for i, spec := range strings.Split(specs, ";") {
+		files = append(files, os.NewFile(uintptr(listenFdBase+i), spec))
+	}
+	return files, parent
+}
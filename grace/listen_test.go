@@ -0,0 +1,20 @@
+package grace_test
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestListenUnixRelisten(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), `grace.sock`)
+	for i := 0; i < 2; i++ {
+		g := newGrace()
+		l, err := g.Listen(`unix`, addr)
+		if err != nil {
+			t.Fatalf("listen %d: %v", i, err)
+		}
+		if err = l.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+//go:build !windows
+// +build !windows
+
+package grace_test
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple/grace"
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+)
+
+const envInheritChild = `GRACE_TEST_INHERIT`
+
+func TestListenInherit(t *testing.T) {
+	if os.Getenv(envInheritChild) != "" {
+		inheritChild(t)
+		return
+	}
+	files := make([]*os.File, 0, 2)
+	specs := ""
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen(`tcp`, `127.0.0.1:0`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = l.Close() }()
+		f, err := l.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = f.Close() }()
+		files = append(files, f)
+		if i > 0 {
+			specs += ";"
+		}
+		specs += `tcp:` + l.Addr().String()
+	}
+	cmd := exec.Command(os.Args[0], "-test.run=^TestListenInherit$")
+	cmd.Env = append(os.Environ(), envInheritChild+"=1", `GRACE_LISTENERS=`+specs)
+	cmd.ExtraFiles = files
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("child: %v\n%s", err, out)
+	}
+}
+
+// inheritChild 在子进程中运行：Run 之前取用第一个继承的监听器，Run 之后取用第二个
+func inheritChild(t *testing.T) {
+	first, second := inheritedAddr(3), inheritedAddr(4)
+	g := newGrace()
+	g.AddContext(`base`, oneShot{}) // 未实现 Readier，调用 Start 前即视为就绪
+	l, err := g.Listen(`tcp`, first)
+	if err != nil {
+		t.Fatalf("listen before run: %v", err)
+	}
+	defer func() { _ = l.Close() }()
+	if err = g.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = g.Stop() }()
+	if _, err = g.Listen(`tcp`, second); !errors.Is(err, grace.ErrListenLate) {
+		t.Errorf("listen after run error %v, want %v", err, grace.ErrListenLate)
+	}
+}
+
+// inheritedAddr 返回父进程传递的文件描述符的监听地址
+func inheritedAddr(fd uintptr) string {
+	l, err := net.FileListener(os.NewFile(fd, ``))
+	if err != nil {
+		return ``
+	}
+	defer func() { _ = l.Close() }()
+	return l.Addr().String()
+}
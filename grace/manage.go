@@ -0,0 +1,125 @@
+package grace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// 运行时管理服务的错误定义
+var (
+	ErrNotFound   = errors.New(`grace: service not found`)             // 服务不存在
+	ErrNotRunning = errors.New(`grace: dependency not running`)        // 依赖的服务未运行
+	ErrInUse      = errors.New(`grace: service depended on by others`) // 服务被其他服务依赖
+)
+
+// StartService 启动单个服务并等待其就绪，服务已在运行时直接返回
+// 依赖的服务必须处于运行中，否则返回 ErrNotRunning；可用于启动 Run 之后添加或被 StopService 停止的服务
+func (g *Grace) StartService(name string) error {
+	srv := g.find(name)
+	if srv == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	for _, dep := range srv.dependsOn() {
+		d := g.find(dep)
+		if d == nil {
+			return fmt.Errorf("%w: %s depends on %s", ErrUnknown, name, dep)
+		}
+		if d.Status().State != StateRunning {
+			return fmt.Errorf("%w: %s depends on %s", ErrNotRunning, name, dep)
+		}
+	}
+	srv.start()
+	return g.waitReady(srv)
+}
+
+// StopService 停止单个服务并等待其退出，停止时间受 WithShutdownTimeout 与 StopTimeout 限制
+// 仍有运行中的服务依赖该服务时不停止并返回 ErrInUse
+func (g *Grace) StopService(name string) error {
+	srv := g.find(name)
+	if srv == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	for _, other := range g.dependents(name) {
+		if atomic.LoadInt32(&other.running) == 1 {
+			return fmt.Errorf("%w: %s used by %s", ErrInUse, name, other.name)
+		}
+	}
+	ctx, cancel := g.stopContext()
+	defer cancel()
+	if err := srv.shutdown(ctx); err != nil {
+		return &StopError{Services: []string{name}}
+	}
+	return nil
+}
+
+// Remove 停止并移除服务，仍有其他服务声明依赖该服务时不移除并返回 ErrInUse
+func (g *Grace) Remove(name string) error {
+	if g.find(name) == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, name)
+	}
+	if others := g.dependents(name); len(others) > 0 {
+		return fmt.Errorf("%w: %s used by %s", ErrInUse, name, others[0].name)
+	}
+	if err := g.StopService(name); err != nil {
+		return err
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i, srv := range g.srv {
+		if srv.name == name {
+			g.srv = append(g.srv[:i], g.srv[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Services 返回所有服务的状态，按优先级和名称排序
+func (g *Grace) Services() []Status {
+	all := g.prioritized()
+	list := make([]Status, 0, len(all))
+	for _, srv := range all {
+		list = append(list, srv.Status())
+	}
+	return list
+}
+
+/*
+  内部方法
+*/
+
+// find 按名称查找服务，不存在时返回 nil
+func (g *Grace) find(name string) *Service {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for _, srv := range g.srv {
+		if srv.name == name {
+			return srv
+		}
+	}
+	return nil
+}
+
+// dependents 返回声明依赖 name 的服务
+func (g *Grace) dependents(name string) []*Service {
+	list := make([]*Service, 0)
+	for _, srv := range g.prioritized() {
+		for _, dep := range srv.dependsOn() {
+			if dep == name {
+				list = append(list, srv)
+				break
+			}
+		}
+	}
+	return list
+}
+
+// stopContext 返回受 WithShutdownTimeout 限制的停止上下文
+func (g *Grace) stopContext() (context.Context, context.CancelFunc) {
+	if g.timeout > 0 {
+		return context.WithTimeout(context.Background(), g.timeout)
+	}
+	return context.WithCancel(context.Background())
+}
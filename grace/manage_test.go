@@ -0,0 +1,43 @@
+package grace_test
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// blocker 的 Start 阻塞到服务停止
+type blocker struct{}
+
+func (blocker) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (blocker) Stop(context.Context) error { return nil }
+
+func TestDependsOnConcurrent(t *testing.T) {
+	g := newGrace()
+	g.AddContext(`base`, blocker{})
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = g.Stop() }()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			g.AddContext(`plugin-`+strconv.Itoa(i), blocker{}).DependsOn(`base`)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = g.StopService(`plugin-` + strconv.Itoa(i))
+			_ = g.StartService(`plugin-` + strconv.Itoa(i))
+		}
+	}()
+	wg.Wait()
+}
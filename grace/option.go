@@ -1,6 +1,9 @@
 package grace
 
-import "time"
+import (
+	"github.com/azeroth-sha/simple/studio"
+	"time"
+)
 
 type Option func(s *Grace)
 
@@ -12,3 +15,37 @@ func WithInterval(d time.Duration) Option {
 		g.interval = d
 	}
 }
+
+// WithShutdownTimeout 设置 Wait 停止服务的最长时间，超时后强制退出进程
+func WithShutdownTimeout(d time.Duration) Option {
+	return func(g *Grace) {
+		if d < 0 {
+			return
+		}
+		g.timeout = d
+	}
+}
+
+// WithReload 设置收到 SIGHUP 时调用的重载函数
+func WithReload(f func() error) Option {
+	return func(g *Grace) {
+		g.reload = f
+	}
+}
+
+// WithReadyTimeout 设置 Run 等待每个服务就绪的最长时间，为 0 时不限制
+func WithReadyTimeout(d time.Duration) Option {
+	return func(g *Grace) {
+		if d < 0 {
+			return
+		}
+		g.ready = d
+	}
+}
+
+// WithStudio 设置发布生命周期事件的 studio.Studio，事件名称为 EventKind.String()，参数为 Event
+func WithStudio(s studio.Studio) Option {
+	return func(g *Grace) {
+		g.studio = s
+	}
+}
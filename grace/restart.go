@@ -0,0 +1,115 @@
+package grace
+
+import (
+	"errors"
+	"fmt"
+	"github.com/azeroth-sha/simple/rand"
+	"math"
+	"time"
+)
+
+// ErrFailed 表示设置了 Fatal 的服务失败，Grace 随之停止
+var ErrFailed = errors.New(`grace: service failed`)
+
+// RestartMode 表示服务 Start 返回后的重启方式
+type RestartMode uint8
+
+const (
+	RestartAlways    RestartMode = iota // 无论 Start 是否返回错误都重新启动
+	RestartOnFailure                    // 仅在 Start 返回错误或恐慌时重新启动
+	RestartNever                        // 从不重新启动
+)
+
+// RestartPolicy 表示服务的重启策略
+type RestartPolicy struct {
+	Mode       RestartMode   // 重启方式
+	MaxRetries int           // 连续失败的最大重试次数，为 0 时不限制
+	MinBackoff time.Duration // 首次重试前的等待时长
+	MaxBackoff time.Duration // 重试等待时长的上限
+	Jitter     float64       // 等待时长的随机抖动比例，取值 0~1
+	ResetAfter time.Duration // 单次运行超过该时长视为稳定，连续失败计数清零；为 0 时不清零
+	Fatal      bool          // 重试次数用尽后停止整个 Grace
+}
+
+// DefaultRestart 返回默认的重启策略：总是重启，等待时长自 1 秒起指数增长至 1 分钟
+func DefaultRestart() RestartPolicy {
+	return RestartPolicy{
+		Mode:       RestartAlways,
+		MaxRetries: 0,
+		MinBackoff: time.Second,
+		MaxBackoff: time.Minute,
+		Jitter:     0.2,
+		ResetAfter: time.Minute,
+		Fatal:      false,
+	}
+}
+
+// Restart 设置服务的重启策略
+func (s *Service) Restart(p RestartPolicy) *Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = p
+	return s
+}
+
+// Done 返回一个通道，当设置了 Fatal 的服务失败时关闭，Wait 随之停止所有服务
+func (g *Grace) Done() <-chan struct{} {
+	return g.down
+}
+
+// Err 返回使 Done 关闭的服务失败错误，可通过 errors.Is(err, ErrFailed) 判断；Done 未关闭时返回 nil
+func (g *Grace) Err() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.err
+}
+
+/*
+  内部方法
+*/
+
+// retry 根据重启策略判断是否重新启动，返回重试前的等待时长
+func (p RestartPolicy) retry(err error, failures int) (time.Duration, bool) {
+	switch {
+	case p.Mode == RestartNever:
+		return 0, false
+	case p.Mode == RestartOnFailure && err == nil:
+		return 0, false
+	case err != nil && p.MaxRetries > 0 && failures > p.MaxRetries:
+		return 0, false
+	}
+	return p.backoff(failures), true
+}
+
+// backoff 计算第 failures 次连续失败后的等待时长：指数增长并加入随机抖动
+func (p RestartPolicy) backoff(failures int) time.Duration {
+	if p.MinBackoff <= 0 {
+		return 0
+	}
+	d := float64(p.MinBackoff)
+	if failures > 1 {
+		d *= math.Pow(2, float64(failures-1))
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		d = float64(p.MaxBackoff)
+	}
+	if p.Jitter > 0 {
+		frac := float64(rand.Uint32()) / math.MaxUint32 // 0~1
+		d += d * p.Jitter * (frac*2 - 1)
+	}
+	return time.Duration(d)
+}
+
+// fail 处理重试次数用尽的服务，设置了 Fatal 时停止整个 Grace
+func (g *Grace) fail(srv *Service) {
+	if !srv.getPolicy().Fatal {
+		return
+	}
+	g.logger.Errorf("service %s failed, grace going down", srv.name)
+	g.downOnce.Do(func() {
+		g.mu.Lock()
+		g.err = fmt.Errorf("%w: %s: %w", ErrFailed, srv.name, srv.failure())
+		g.mu.Unlock()
+		close(g.down)
+	})
+}
@@ -1,7 +1,9 @@
 package grace
 
 import (
+	"context"
 	"github.com/azeroth-sha/simple"
+	"runtime/debug"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -12,63 +14,352 @@ type Server interface {
 	Stop() error
 }
 
-type service struct {
+// ContextServer 是支持上下文的服务接口
+// Start 的 ctx 在服务停止时被取消；Stop 的 ctx 在停止期限到达时被取消
+type ContextServer interface {
+	Start(ctx context.Context) error
+	Stop(ctx context.Context) error
+}
+
+// plainServer 将 Server 适配为 ContextServer
+type plainServer struct {
+	Server
+}
+
+func (p plainServer) Start(context.Context) error {
+	return p.Server.Start()
+}
+
+func (p plainServer) Stop(context.Context) error {
+	return p.Server.Stop()
+}
+
+// Service 表示一个由 Grace 管理的服务
+type Service struct {
 	running  int32
 	name     string
-	server   Server
+	impl     any
+	server   ContextServer
 	priority int
+	depends  []string
+	ready    chan struct{}
+	once     *sync.Once
 	logger   simple.Logger
 	wait     *sync.WaitGroup
+	mu       *sync.Mutex
+	policy   RestartPolicy
+	onFail   func(*Service)
+	emit     func(Event)
+	cancel   context.CancelFunc
+	exited   chan struct{}
+	stopTime time.Duration
+	seq      uint64
+	state    State
+	since    time.Time
+	lastErr  error
 }
 
-func (s *service) start() {
-	go s.run()
+// Name 返回服务名称
+func (s *Service) Name() string {
+	return s.name
+}
+
+// StopTimeout 设置服务停止的最长时间，超时后 Grace 不再等待该服务
+func (s *Service) StopTimeout(d time.Duration) *Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.stopTime = d
+	return s
 }
 
-func (s *service) run() {
-	if atomic.SwapInt32(&s.running, 1) != 0 {
+// DependsOn 声明服务依赖的其他服务，Grace 在依赖就绪后才启动该服务，并先于依赖停止该服务
+func (s *Service) DependsOn(names ...string) *Service {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.depends = append(s.depends, names...)
+	return s
+}
+
+// start 在服务未运行时启动服务，重新启动前重置就绪信号
+func (s *Service) start() {
+	if !atomic.CompareAndSwapInt32(&s.running, 0, 1) {
 		return
 	}
+	s.mu.Lock()
+	select {
+	case <-s.ready:
+		s.ready = make(chan struct{})
+		s.once = new(sync.Once)
+	default:
+	}
+	s.mu.Unlock()
 	s.wait.Add(1)
+	go s.run()
+}
+
+func (s *Service) run() {
 	defer s.wait.Done()
-	defer s.stop()
+	ctx, exited := s.begin()
+	defer close(exited)
+	defer func() { _ = s.stop(context.Background()) }()
+	defer s.notify(EventServiceStopped, nil, 0)
+	defer s.release()
 	defer s.logger.Infof("service %s exited", s.name)
 	s.logger.Infof("service %s starting", s.name)
+	s.setState(StateStarting, nil)
+	s.notify(EventServiceStarting, nil, 0)
+	failures := 0
 	for atomic.LoadInt32(&s.running) == 1 {
-		func() {
-			defer func() {
-				if rec := recover(); rec != nil {
-					s.logger.Errorf("service %s panic: %v", s.name, rec)
-				}
-			}()
-			if err := s.server.Start(); err != nil {
-				s.logger.Errorf("service %s start error: %v", s.name, err)
-				if atomic.LoadInt32(&s.running) == 1 {
-					time.Sleep(time.Second)
-				}
+		seq, done := s.nextSeq(), make(chan struct{})
+		if r, ok := s.impl.(Readier); ok {
+			go s.watch(r, seq, done)
+		} else {
+			s.markReady(seq) // 未实现 Readier 的服务在调用 Start 前即视为就绪
+		}
+		begin := time.Now()
+		err := s.attempt(ctx)
+		close(done)
+		if err == nil {
+			s.markReady(seq) // Start 正常返回的服务视为已就绪，避免短时任务阻塞 Run
+		}
+		s.nextSeq() // 使本次启动的就绪通知失效
+		if atomic.LoadInt32(&s.running) != 1 {
+			break
+		}
+		policy := s.getPolicy()
+		if policy.ResetAfter > 0 && time.Since(begin) >= policy.ResetAfter {
+			failures = 0 // 稳定运行后清零连续失败计数
+		}
+		if err != nil {
+			failures++
+			s.notify(EventServiceCrashed, err, 0)
+		}
+		wait, ok := policy.retry(err, failures)
+		if !ok {
+			if err == nil {
+				s.setState(StateStopped, nil)
+			} else {
+				s.logger.Errorf("service %s failed after %d attempts", s.name, failures)
+				s.setState(StateFailed, err)
+				s.notify(EventServiceFailed, err, 0)
+				s.onFail(s)
 			}
-		}()
+			return
+		}
+		s.setState(StateRestarting, err)
+		s.logger.Infof("service %s restarting in %s", s.name, wait)
+		s.notify(EventServiceRestarting, err, wait)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+		}
+	}
+	s.setState(StateStopped, nil)
+}
+
+// attempt 调用一次 Start，恐慌被转换为 *PanicError
+func (s *Service) attempt(ctx context.Context) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			s.logger.Errorf("service %s panic: %v", s.name, rec)
+			err = &PanicError{Value: rec, Stack: debug.Stack()}
+		}
+	}()
+	if err = s.server.Start(ctx); err != nil {
+		s.logger.Errorf("service %s start error: %v", s.name, err)
+	}
+	return err
+}
+
+// watch 等待 Readier 就绪后将状态置为运行中，seq 为本次启动的序号，done 关闭表示本次启动已结束
+func (s *Service) watch(r Readier, seq uint64, done <-chan struct{}) {
+	select {
+	case <-r.Ready():
+		s.markReady(seq)
+	case <-done:
+	}
+}
+
+// markReady 将状态置为运行中并关闭就绪信号，seq 与当前启动序号不一致时忽略
+func (s *Service) markReady(seq uint64) {
+	s.mu.Lock()
+	if s.seq != seq {
+		s.mu.Unlock()
+		return // 本次启动已结束
+	}
+	started := s.state != StateRunning
+	if started {
+		s.state = StateRunning
+		s.since = time.Now()
+	}
+	s.once.Do(func() { close(s.ready) })
+	s.mu.Unlock()
+	if started {
+		s.notify(EventServiceStarted, nil, 0)
+	}
+}
+
+// release 在运行结束时关闭就绪信号，使等待就绪的调用方不再阻塞
+func (s *Service) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.once.Do(func() { close(s.ready) })
+}
+
+// failure 返回服务失败时的错误，服务未失败时返回 nil
+func (s *Service) failure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != StateFailed {
+		return nil
+	}
+	return s.lastErr
+}
+
+// dependsOn 返回服务依赖的服务名称的副本
+func (s *Service) dependsOn() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.depends...)
+}
+
+// readyChan 返回本次启动的就绪信号通道
+func (s *Service) readyChan() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ready
+}
+
+// getPolicy 返回服务的重启策略
+func (s *Service) getPolicy() RestartPolicy {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.policy
+}
+
+// begin 创建本次运行的上下文与退出信号通道
+func (s *Service) begin() (context.Context, chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+	s.exited = make(chan struct{})
+	return ctx, s.exited
+}
+
+// end 取消本次运行的上下文，返回运行结束信号通道
+func (s *Service) end() <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	return s.exited
+}
+
+// getStopTimeout 返回服务停止的最长时间
+func (s *Service) getStopTimeout() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stopTime
+}
+
+// nextSeq 递增并返回启动序号
+func (s *Service) nextSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	return s.seq
+}
+
+// setState 更新服务状态，err 为空时保留上一次的错误
+func (s *Service) setState(state State, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != state {
+		s.state = state
+		s.since = time.Now()
+	}
+	if err != nil {
+		s.lastErr = err
 	}
 }
 
-func (s *service) stop() {
+// stop 停止服务，ctx 结束时不再等待 Stop 返回，返回 ctx 的错误
+func (s *Service) stop(ctx context.Context) error {
 	if atomic.SwapInt32(&s.running, 0) != 1 {
-		return
+		return nil
 	}
+	s.end()
 	s.logger.Infof("service %s stop", s.name)
-	defer s.logger.Infof("service %s stopped", s.name)
-	if err := s.server.Stop(); err != nil {
-		s.logger.Errorf("service %s stop error: %v", s.name, err)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if err := s.server.Stop(ctx); err != nil {
+			s.logger.Errorf("service %s stop error: %v", s.name, err)
+		}
+	}()
+	select {
+	case <-done:
+		s.logger.Infof("service %s stopped", s.name)
+		return nil
+	case <-ctx.Done():
+		s.logger.Errorf("service %s stop timeout: %v", s.name, ctx.Err())
+		return ctx.Err()
 	}
 }
 
-func newServ(name string, server Server, logger simple.Logger, wait *sync.WaitGroup, priority ...int) *service {
-	serv := &service{
+// shutdown 停止服务并等待运行结束，超过服务的停止时长或 ctx 结束时返回错误
+func (s *Service) shutdown(ctx context.Context) error {
+	if d := s.getStopTimeout(); d > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d)
+		defer cancel()
+	}
+	if err := s.stop(ctx); err != nil {
+		return err
+	}
+	exited := s.end()
+	if exited == nil {
+		return nil // 服务未启动
+	}
+	select {
+	case <-exited:
+		return nil
+	case <-ctx.Done():
+		s.logger.Errorf("service %s exit timeout: %v", s.name, ctx.Err())
+		return ctx.Err()
+	}
+}
+
+func newServ(name string, server any, logger simple.Logger, wait *sync.WaitGroup, priority ...int) *Service {
+	serv := &Service{
 		name:     name,
-		server:   server,
+		impl:     server,
+		server:   nil,
 		logger:   logger,
 		wait:     wait,
 		priority: 0,
+		depends:  make([]string, 0),
+		ready:    make(chan struct{}),
+		once:     new(sync.Once),
+		mu:       new(sync.Mutex),
+		state:    StateStopped,
+		since:    time.Now(),
+		lastErr:  nil,
+		policy:   DefaultRestart(),
+		onFail:   func(*Service) {},
+		emit:     func(Event) {},
+		cancel:   nil,
+		exited:   nil,
+		stopTime: 0,
+	}
+	switch v := server.(type) {
+	case ContextServer:
+		serv.server = v
+	case Server:
+		serv.server = plainServer{Server: v}
 	}
 	if len(priority) > 0 {
 		serv.priority = priority[0]
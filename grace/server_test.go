@@ -0,0 +1,85 @@
+package grace_test
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple/grace"
+	"testing"
+	"time"
+)
+
+// nopLogger 丢弃所有日志
+type nopLogger struct{}
+
+func (nopLogger) Debug(...interface{})          {}
+func (nopLogger) Info(...interface{})           {}
+func (nopLogger) Warn(...interface{})           {}
+func (nopLogger) Error(...interface{})          {}
+func (nopLogger) Fatal(...interface{})          {}
+func (nopLogger) Debugf(string, ...interface{}) {}
+func (nopLogger) Infof(string, ...interface{})  {}
+func (nopLogger) Warnf(string, ...interface{})  {}
+func (nopLogger) Errorf(string, ...interface{}) {}
+func (nopLogger) Fatalf(string, ...interface{}) {}
+
+// oneShot 的 Start 立即返回
+type oneShot struct {
+	err error
+}
+
+func (o oneShot) Start(context.Context) error { return o.err }
+func (o oneShot) Stop(context.Context) error  { return nil }
+
+// readyShot 实现 Readier 但从不就绪
+type readyShot struct {
+	oneShot
+}
+
+func (readyShot) Ready() <-chan struct{} { return nil }
+
+func newGrace(opts ...grace.Option) *grace.Grace {
+	return grace.New(nopLogger{}, append([]grace.Option{grace.WithInterval(0), grace.WithReadyTimeout(time.Second * 5)}, opts...)...)
+}
+
+func TestShortLived(t *testing.T) {
+	never := grace.RestartPolicy{Mode: grace.RestartNever}
+	for i := 0; i < 50; i++ {
+		g := newGrace()
+		g.AddContext(`job`, oneShot{}).Restart(never)
+		begin := time.Now()
+		if err := g.Run(); err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		if d := time.Since(begin); d > time.Second {
+			t.Fatalf("run took %s", d)
+		}
+		_ = g.Stop()
+	}
+}
+
+func TestStartServiceShortLived(t *testing.T) {
+	g := newGrace()
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = g.Stop() }()
+	g.AddContext(`job`, oneShot{}).Restart(grace.RestartPolicy{Mode: grace.RestartNever})
+	for i := 0; i < 50; i++ {
+		if err := g.StartService(`job`); err != nil {
+			t.Fatalf("start service: %v", err)
+		}
+	}
+}
+
+func TestFailedBeforeReady(t *testing.T) {
+	g := newGrace()
+	g.AddContext(`job`, readyShot{oneShot{err: errors.New(`boom`)}}).Restart(grace.RestartPolicy{Mode: grace.RestartNever})
+	begin := time.Now()
+	if err := g.Run(); !errors.Is(err, grace.ErrNotReady) {
+		t.Errorf("run error %v, want %v", err, grace.ErrNotReady)
+	}
+	if d := time.Since(begin); d > time.Second {
+		t.Errorf("run took %s", d)
+	}
+	_ = g.Stop()
+}
@@ -0,0 +1,127 @@
+package grace
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// exit 强制退出进程，测试时可替换
+var exit = os.Exit
+
+// Wait 等待退出信号并停止所有服务
+// 收到 SIGINT、SIGTERM、SIGQUIT，ctx 结束或 Done 关闭时调用 Stop；
+// 停止期间再次收到退出信号，或超过 WithShutdownTimeout 设置的时长时强制退出进程；
+// 服务超过自身的 StopTimeout 仅记录日志并发送 EventServiceStopTimeout 事件，不会强制退出；
+// 设置了 WithReload 时，收到 SIGHUP 调用重载函数；
+// 收到 SIGUSR2 时调用 Upgrade 热升级，子进程就绪后发送的 SIGTERM 使当前进程停止。
+func (g *Grace) Wait(ctx context.Context) {
+	sig := make(chan os.Signal, 2)
+	signals := []os.Signal{syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT}
+	if g.reload != nil {
+		signals = append(signals, syscall.SIGHUP)
+	}
+	signals = append(signals, upgradeSignals()...)
+	signal.Notify(sig, signals...)
+	defer signal.Stop(sig)
+	if !g.waitSignal(ctx, sig, g.down) && ctx.Err() != nil {
+		g.logger.Info("grace context done")
+	}
+	again, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stop, stopCancel := g.stopContext()
+	defer stopCancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- g.Shutdown(stop)
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			return
+		}
+		if stop.Err() != nil { // 超过整体停止期限，仍有服务未退出
+			g.logger.Errorf("%v, force exit", err)
+			exit(1)
+		}
+		g.logger.Errorf("grace stop: %v", err) // 仅个别服务超过 StopTimeout，不强制退出
+	case <-stop.Done():
+		g.logger.Errorf("grace stop timeout after %s, force exit", g.timeout)
+		exit(1)
+	case <-g.signalOnce(again, sig):
+		g.logger.Error("grace receive signal again, force exit")
+		exit(1)
+	}
+}
+
+// RunAndWait 启动所有服务并等待退出信号
+// 设置了 Fatal 的服务失败导致停止时，返回包含服务名称与失败原因的 ErrFailed
+func (g *Grace) RunAndWait() error {
+	if err := g.Run(); err != nil {
+		_ = g.Stop()
+		return err
+	}
+	g.Wait(context.Background())
+	return g.Err()
+}
+
+/*
+  内部方法
+*/
+
+// waitSignal 等待退出信号，SIGHUP 触发重载、SIGUSR2 触发热升级后继续等待；收到退出信号返回 true，ctx 结束或 down 关闭返回 false
+func (g *Grace) waitSignal(ctx context.Context, sig <-chan os.Signal, down <-chan struct{}) bool {
+	for {
+		select {
+		case s := <-sig:
+			if s == syscall.SIGHUP {
+				g.doReload()
+				continue
+			}
+			if isUpgrade(s) {
+				g.doUpgrade()
+				continue
+			}
+			g.logger.Infof("grace receive signal %s", s)
+			return true
+		case <-ctx.Done():
+			return false
+		case <-down:
+			return false
+		}
+	}
+}
+
+// signalOnce 返回一个在下一次退出信号到达时关闭的通道，期间的 SIGHUP 与 SIGUSR2 仍被处理
+// 停止期间 Done 已关闭，因此不随 Done 返回，直到 ctx 结束都会响应退出信号
+func (g *Grace) signalOnce(ctx context.Context, sig <-chan os.Signal) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		if g.waitSignal(ctx, sig, nil) {
+			close(ch)
+		}
+	}()
+	return ch
+}
+
+// doReload 执行重载函数
+func (g *Grace) doReload() {
+	g.logger.Info("grace reload")
+	defer func() {
+		if rec := recover(); rec != nil {
+			g.logger.Errorf("grace reload panic: %v", rec)
+		}
+	}()
+	if err := g.reload(); err != nil {
+		g.logger.Errorf("grace reload error: %v", err)
+	}
+}
+
+// doUpgrade 执行热升级
+func (g *Grace) doUpgrade() {
+	g.logger.Info("grace upgrade")
+	if err := g.Upgrade(); err != nil {
+		g.logger.Errorf("grace upgrade error: %v", err)
+	}
+}
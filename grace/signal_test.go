@@ -0,0 +1,90 @@
+package grace_test
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple/grace"
+	"strings"
+	"testing"
+	"time"
+)
+
+// crasher 的 Start 在运行一段时间后返回错误
+type crasher struct {
+	after time.Duration
+}
+
+func (c crasher) Start(ctx context.Context) error {
+	select {
+	case <-time.After(c.after):
+		return errors.New(`boom`)
+	case <-ctx.Done():
+		return nil
+	}
+}
+
+func (c crasher) Stop(context.Context) error { return nil }
+
+func TestRunAndWaitFatal(t *testing.T) {
+	g := newGrace()
+	g.AddContext(`worker`, crasher{after: time.Millisecond * 50}).Restart(grace.RestartPolicy{
+		Mode:  grace.RestartNever,
+		Fatal: true,
+	})
+	done := make(chan error, 1)
+	go func() { done <- g.RunAndWait() }()
+	select {
+	case err := <-done:
+		if !errors.Is(err, grace.ErrFailed) || !strings.Contains(err.Error(), `worker`) || !strings.Contains(err.Error(), `boom`) {
+			t.Errorf("run and wait error %v, want %v for worker", err, grace.ErrFailed)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("run and wait did not return")
+	}
+}
+
+// stuck 的 Stop 在 ctx 结束前不返回
+type stuck struct {
+	quit chan struct{}
+}
+
+func (s stuck) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+func (s stuck) Stop(ctx context.Context) error {
+	select {
+	case <-s.quit:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func TestWaitStopTimeout(t *testing.T) {
+	g := newGrace()
+	quit := make(chan struct{})
+	defer close(quit)
+	g.AddContext(`stuck`, stuck{quit: quit}).StopTimeout(time.Millisecond * 50)
+	events, cancel := g.Subscribe(16)
+	defer cancel()
+	if err := g.Run(); err != nil {
+		t.Fatal(err)
+	}
+	ctx, stop := context.WithCancel(context.Background())
+	stop()
+	g.Wait(ctx) // 服务超过 StopTimeout 时不应强制退出进程
+	for {
+		select {
+		case ev := <-events:
+			if ev.Kind == grace.EventServiceStopTimeout {
+				if ev.Service != `stuck` || ev.Err == nil {
+					t.Errorf("stop timeout event %+v", ev)
+				}
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatal("no stop timeout event")
+		}
+	}
+}
@@ -0,0 +1,93 @@
+//go:build !windows
+// +build !windows
+
+package grace
+
+import (
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+// Upgrade 热升级：以相同的参数重新执行当前程序，并将 Listen 创建的监听器传递给子进程
+// 子进程的 Run 成功后向父进程发送 SIGTERM，父进程随之停止服务并退出，期间监听器不中断
+func (g *Grace) Upgrade() error {
+	g.mu.Lock()
+	ls := make([]*listener, len(g.listeners))
+	copy(ls, g.listeners)
+	g.mu.Unlock()
+	files := make([]*os.File, 0, len(ls))
+	specs := make([]string, 0, len(ls))
+	defer func() {
+		for _, f := range files {
+			_ = f.Close()
+		}
+	}()
+	for _, l := range ls {
+		f, err := l.file()
+		if err != nil {
+			return err
+		}
+		files = append(files, f)
+		specs = append(specs, l.spec())
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	env := make([]string, 0, len(os.Environ())+2)
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, envListeners+"=") && !strings.HasPrefix(kv, envParent+"=") {
+			env = append(env, kv)
+		}
+	}
+	env = append(env,
+		envListeners+"="+strings.Join(specs, ";"),
+		envParent+"="+strconv.Itoa(os.Getpid()),
+	)
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = env
+	cmd.ExtraFiles = files
+	if err = cmd.Start(); err != nil {
+		return err
+	}
+	for _, l := range ls {
+		l.handover()
+	}
+	g.logger.Infof("grace upgrade, child pid %d", cmd.Process.Pid)
+	return cmd.Process.Release()
+}
+
+/*
+  内部方法
+*/
+
+// upgradeSignals 返回触发热升级的信号
+func upgradeSignals() []os.Signal {
+	return []os.Signal{syscall.SIGUSR2}
+}
+
+// isUpgrade 判断是否为触发热升级的信号
+func isUpgrade(s os.Signal) bool {
+	return s == syscall.SIGUSR2
+}
+
+// notifyParent 通知热升级的父进程退出
+func (g *Grace) notifyParent() {
+	g.mu.Lock()
+	parent := g.parent
+	g.parent = 0
+	g.mu.Unlock()
+	if parent <= 0 {
+		return
+	}
+	g.logger.Infof("grace notify parent %d", parent)
+	if err := syscall.Kill(parent, syscall.SIGTERM); err != nil {
+		g.logger.Errorf("grace notify parent error: %v", err)
+	}
+}
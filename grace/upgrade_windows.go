@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package grace
+
+import (
+	"os"
+)
+
+// Upgrade 热升级，Windows 不支持，返回 ErrUpgrade
+func (g *Grace) Upgrade() error {
+	return ErrUpgrade
+}
+
+/*
+  内部方法
+*/
+
+// upgradeSignals 返回触发热升级的信号，Windows 没有
+func upgradeSignals() []os.Signal {
+	return nil
+}
+
+// isUpgrade 判断是否为触发热升级的信号
+func isUpgrade(os.Signal) bool {
+	return false
+}
+
+// notifyParent 通知热升级的父进程退出，Windows 无需处理
+func (g *Grace) notifyParent() {}
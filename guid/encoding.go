@@ -0,0 +1,239 @@
+package guid
+
+import (
+	"fmt"
+	"math/bits"
+	"os"
+)
+
+// Encoding is a text encoding of GUID.
+// All encodings are fixed-width with alphabets in ascending byte order, so encoded IDs sort like the IDs.
+// String, MarshalText, MarshalJSON and Value always use base36; other encodings are used only through Encode and Decode,
+// since text of the same length in two encodings cannot be told apart.
+type Encoding uint8
+
+const (
+	Base36    Encoding = iota // 0-9a-z，20个字符，默认编码
+	Base32                    // Crockford base32，20个字符
+	Base58                    // Bitcoin base58，17个字符
+	Base62                    // 0-9A-Za-z，17个字符
+	Hex                       // 小写十六进制，24个字符
+	encodings                 // 编码数量
+)
+
+// String returns the name of the encoding.
+func (e Encoding) String() string {
+	switch e {
+	case Base36:
+		return "base36"
+	case Base32:
+		return "base32"
+	case Base58:
+		return "base58"
+	case Base62:
+		return "base62"
+	case Hex:
+		return "hex"
+	default:
+		return fmt.Sprintf("encoding(%d)", uint8(e))
+	}
+}
+
+// Len returns the length of a GUID in the encoding.
+func (e Encoding) Len() int {
+	return e.width(BLen)
+}
+
+// ParseWith parses a GUID from a string in the encoding.
+func ParseWith(s string, e Encoding) (GUID, error) {
+	var id GUID
+	return id, id.Decode([]byte(s), e)
+}
+
+// Encode returns the GUID in the encoding.
+func (g GUID) Encode(e Encoding) string {
+	var buf [BLen * 2]byte
+	return string(g.AppendEncode(buf[:0], e))
+}
+
+// AppendEncode appends the GUID in the encoding to dst, it does not allocate if dst has enough capacity.
+func (g GUID) AppendEncode(dst []byte, e Encoding) []byte {
+	n := e.width(BLen)
+	if n == 0 {
+		return dst
+	}
+	dst = grow(dst, n)
+	encodeTo(dst[len(dst)-n:], g[:], &alphabets[e])
+	return dst
+}
+
+// Decode decodes the GUID from text in the encoding.
+func (g *GUID) Decode(text []byte, e Encoding) error {
+	if e >= encodings || len(text) != e.width(BLen) {
+		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+	}
+	return decodeTo(g[:], text, &alphabets[e])
+}
+
+/*
+  Package Private functions
+*/
+
+// alphabet is the digits of an encoding and their reverse lookup.
+type alphabet struct {
+	base   uint32
+	digits string
+	values [256]byte // 0xff for invalid characters
+	widths [2]int    // encoded length of 12 and 16 bytes
+	shift  uint      // bits per digit when base is a power of two, otherwise 0
+	chunk  int       // digits produced per long division
+	div    uint64    // base^chunk, the largest power of base below 2^32
+}
+
+var alphabets [encodings]alphabet
+
+func init() {
+	alphabets[Base36] = newAlphabet("0123456789abcdefghijklmnopqrstuvwxyz", SLen, WideSLen)
+	alphabets[Base32] = newAlphabet("0123456789ABCDEFGHJKMNPQRSTVWXYZ", 20, 26)
+	alphabets[Base58] = newAlphabet("123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz", 17, 22)
+	alphabets[Base62] = newAlphabet("0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz", 17, 22)
+	alphabets[Hex] = newAlphabet("0123456789abcdef", 24, 32)
+	for _, a := range []*alphabet{&alphabets[Base36], &alphabets[Hex]} {
+		for c := 'a'; c <= 'z'; c++ {
+			a.values[c-'a'+'A'] = a.values[c] // 不区分大小写
+		}
+	}
+	b32 := &alphabets[Base32]
+	for c := 'A'; c <= 'Z'; c++ {
+		b32.values[c-'A'+'a'] = b32.values[c] // 不区分大小写
+	}
+	for _, c := range "IiLl" {
+		b32.values[c] = 1 // Crockford: I、L 视为 1
+	}
+	b32.values['O'], b32.values['o'] = 0, 0 // Crockford: O 视为 0
+}
+
+func newAlphabet(digits string, w12, w16 int) alphabet {
+	a := alphabet{
+		base:   uint32(len(digits)),
+		digits: digits,
+		widths: [2]int{w12, w16},
+		div:    1,
+	}
+	if a.base&(a.base-1) == 0 {
+		a.shift = uint(bits.TrailingZeros32(a.base))
+	}
+	for a.div*uint64(a.base) < 1<<32 {
+		a.div *= uint64(a.base)
+		a.chunk++
+	}
+	for i := range a.values {
+		a.values[i] = 0xff
+	}
+	for i := 0; i < len(digits); i++ {
+		a.values[digits[i]] = byte(i)
+	}
+	return a
+}
+
+// width returns the encoded length of size bytes, 0 if unsupported.
+func (e Encoding) width(size int) int {
+	if e >= encodings {
+		return 0
+	}
+	switch size {
+	case 12:
+		return alphabets[e].widths[0]
+	case 16:
+		return alphabets[e].widths[1]
+	default:
+		return 0
+	}
+}
+
+// grow extends dst by n bytes.
+func grow(dst []byte, n int) []byte {
+	if cap(dst)-len(dst) < n {
+		buf := make([]byte, len(dst), len(dst)+n)
+		copy(buf, dst)
+		dst = buf
+	}
+	return dst[:len(dst)+n]
+}
+
+// limbs is a big-endian number of up to 128 bits.
+type limbs [4]uint32
+
+// encodeTo writes src, whose length is a multiple of 4 up to 16, as a zero-padded number into dst.
+func encodeTo(dst, src []byte, a *alphabet) {
+	if a.shift > 0 {
+		encodeBits(dst, src, a)
+		return
+	}
+	var n limbs
+	k := len(src) / 4
+	for i := 0; i < k; i++ {
+		n[i] = endian.Uint32(src[i*4:])
+	}
+	for i := len(dst) - 1; i >= 0; {
+		var r uint64 // 每次长除法取出 chunk 位数字，余数在 32 位内继续拆分
+		for j := 0; j < k; j++ {
+			cur := r<<32 | uint64(n[j])
+			n[j] = uint32(cur / a.div)
+			r = cur % a.div
+		}
+		d := uint32(r)
+		for j := 0; j < a.chunk && i >= 0; j++ {
+			dst[i] = a.digits[d%a.base]
+			d /= a.base
+			i--
+		}
+	}
+}
+
+// encodeBits writes src into dst shift bits per digit, from the least significant end.
+func encodeBits(dst, src []byte, a *alphabet) {
+	var acc uint32
+	var have uint
+	s := len(src) - 1
+	mask := uint32(1)<<a.shift - 1
+	for i := len(dst) - 1; i >= 0; i-- {
+		if have < a.shift && s >= 0 {
+			acc |= uint32(src[s]) << have
+			have += 8
+			s--
+		}
+		dst[i] = a.digits[acc&mask]
+		acc >>= a.shift
+		if have >= a.shift {
+			have -= a.shift
+		} else {
+			have = 0
+		}
+	}
+}
+
+// decodeTo parses text as a number into dst, whose length is a multiple of 4 up to 16.
+func decodeTo(dst, text []byte, a *alphabet) error {
+	var n limbs
+	k := len(dst) / 4
+	for _, c := range text {
+		v := a.values[c]
+		if v == 0xff {
+			return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+		}
+		carry := uint64(v)
+		for j := k - 1; j >= 0; j-- {
+			cur := uint64(n[j])*uint64(a.base) + carry
+			n[j] = uint32(cur)
+			carry = cur >> 32
+		}
+		if carry != 0 {
+			return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+		}
+	}
+	for i := 0; i < k; i++ {
+		endian.PutUint32(dst[i*4:], n[i])
+	}
+	return nil
+}
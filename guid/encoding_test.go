@@ -0,0 +1,144 @@
+package guid_test
+
+import (
+	"encoding/json"
+	"github.com/azeroth-sha/simple/guid"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+var encodings = []guid.Encoding{guid.Base36, guid.Base32, guid.Base58, guid.Base62, guid.Hex}
+
+func TestEncoding(t *testing.T) {
+	ids := []guid.GUID{guid.NULL, guid.New()}
+	var max guid.GUID
+	for i := range max {
+		max[i] = 0xff
+	}
+	ids = append(ids, max)
+	for _, e := range encodings {
+		for _, id := range ids {
+			s := id.Encode(e)
+			if len(s) != e.Len() {
+				t.Errorf("%s: length %d, want %d", e, len(s), e.Len())
+			}
+			if got, err := guid.ParseWith(s, e); err != nil || got != id {
+				t.Errorf("%s: parse %s = %v, %v", e, s, got, err)
+			}
+		}
+	}
+	id := guid.New()
+	if id.String() != bigString(id) {
+		t.Errorf("base36 %s, want %s", id.String(), bigString(id))
+	}
+	if got, err := guid.ParseWith(strings.ToUpper(id.Encode(guid.Base36)), guid.Base36); err != nil || got != id {
+		t.Errorf("base36 upper case: %v, %v", got, err)
+	}
+}
+
+func TestEncodingCanonical(t *testing.T) {
+	id := guid.New()
+	for _, e := range encodings {
+		if e == guid.Base36 || e == guid.Hex {
+			continue
+		}
+		// text in another encoding must not decode to the same GUID through the canonical form
+		if got, err := guid.Parse(id.Encode(e)); err == nil && got == id {
+			t.Errorf("%s: parsed as canonical text", e)
+		}
+	}
+	text, _ := id.MarshalText()
+	if string(text) != id.Encode(guid.Base36) {
+		t.Errorf("marshal text %s, want base36 %s", text, id.Encode(guid.Base36))
+	}
+	if got, err := guid.Parse(id.Encode(guid.Hex)); err != nil || got != id {
+		t.Errorf("hex: parse = %v, %v", got, err)
+	}
+	buf, _ := json.Marshal(id)
+	var out guid.GUID
+	if err := json.Unmarshal(buf, &out); err != nil || out != id {
+		t.Errorf("json %s: %v, %v", buf, out, err)
+	}
+}
+
+func TestValueScan(t *testing.T) {
+	id := guid.New()
+	v, err := id.Value()
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, ok := v.(string)
+	if !ok {
+		t.Fatalf("value %T, want string", v)
+	}
+	for _, src := range []any{s, []byte(s), id.Bytes()} {
+		var out guid.GUID
+		if err = out.Scan(src); err != nil || out != id {
+			t.Errorf("scan %T: %v, %v", src, out, err)
+		}
+	}
+}
+
+func BenchmarkStringBig(b *testing.B) {
+	id := guid.New()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = bigString(id)
+	}
+}
+
+func BenchmarkEncode(b *testing.B) {
+	id := guid.New()
+	for _, e := range encodings {
+		b.Run(e.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				_ = id.Encode(e)
+			}
+		})
+	}
+}
+
+func BenchmarkAppendEncode(b *testing.B) {
+	id := guid.New()
+	buf := make([]byte, 0, 32)
+	for _, e := range encodings {
+		b.Run(e.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buf = id.AppendEncode(buf[:0], e)
+			}
+		})
+	}
+}
+
+func BenchmarkParseBig(b *testing.B) {
+	s := guid.New().Encode(guid.Base36)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		n, _ := new(big.Int).SetString(s, guid.Base)
+		var id guid.GUID
+		n.FillBytes(id[:])
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	id := guid.New()
+	for _, e := range encodings {
+		text := []byte(id.Encode(e))
+		b.Run(e.String(), func(b *testing.B) {
+			b.ReportAllocs()
+			var out guid.GUID
+			for i := 0; i < b.N; i++ {
+				_ = out.Decode(text, e)
+			}
+		})
+	}
+}
+
+// bigString is the math/big based base36 encoding that Encode replaces.
+func bigString(id guid.GUID) string {
+	s := new(big.Int).SetBytes(id[:]).Text(guid.Base)
+	return strings.Repeat("0", guid.SLen-len(s)) + s
+}
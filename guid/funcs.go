@@ -9,8 +9,7 @@ import (
 )
 
 var (
-	NULL    GUID // 空GUID
-	hostGen Generator
+	NULL GUID // 空GUID
 )
 
 // New returns a new GUID.
@@ -20,7 +19,20 @@ func New() GUID {
 
 // NewWithTime returns a new GUID with the given time.
 func NewWithTime(tm time.Time) GUID {
-	return hostGen.NewWithTime(tm)
+	return current.Load().guid.NewWithTime(tm)
+}
+
+// SetGenerator replaces the generator used by New and NewWithTime,
+// e.g. with a StrictGenerator to get strictly increasing IDs.
+func SetGenerator(g Generator) {
+	for {
+		old := current.Load()
+		d := *old
+		d.guid = g
+		if current.CompareAndSwap(old, &d) {
+			return
+		}
+	}
 }
 
 // Parse parses a GUID from a string.
@@ -48,9 +60,30 @@ func NewGenerator(mark uint32) Generator {
 */
 
 func init() {
+	if err := SetNodeFromEnv(EnvNode); err == nil {
+		return
+	} else if _, ok := os.LookupEnv(EnvNode); ok {
+		envErr = err // 已设置但无效的节点ID退回主机标识，由 NodeErr 报告
+	}
+	mark := hostMark()
+	useMark(mark, hostNode(mark), SourceHost)
+}
+
+// hostMark returns the host ID and the PID, each folded into 16 bits.
+func hostMark() uint32 {
 	hID := getHostID()
 	pID := uint32(os.Getpid())
-	hostGen = NewGenerator(hID<<16 | pID)
+	return fold16(hID)<<16 | fold16(pID)
+}
+
+func fold16(n uint32) uint32 {
+	return (n ^ n>>16) & 0xffff
+}
+
+// hostNode folds the host ID and the PID of mark into a Snowflake node.
+// Containers often share a PID, so the host bits must take part; set a node ID to rule out collisions.
+func hostNode(mark uint32) uint16 {
+	return uint16((mark ^ mark>>SnowflakeNodeBits ^ mark>>(2*SnowflakeNodeBits) ^ mark>>(3*SnowflakeNodeBits)) & SnowflakeMaxNode)
 }
 
 func getHostID() uint32 {
@@ -9,10 +9,8 @@ import (
 
 var endian = binary.BigEndian
 
-type Generator interface {
-	New() GUID
-	NewWithTime(tm time.Time) GUID
-}
+// Generator is the generator of the default GUID layout.
+type Generator = TypedGenerator[GUID]
 
 type adapter struct {
 	mark   uint32
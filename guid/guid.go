@@ -6,7 +6,6 @@ import (
 	"encoding/binary"
 	"fmt"
 	"os"
-	"strings"
 )
 
 const (
@@ -31,14 +30,7 @@ func (g GUID) Empty() bool {
 
 // String returns the string.
 func (g GUID) String() string {
-	bInt := getInt()
-	defer putInt(bInt)
-	bInt.SetBytes(g[:])
-	id := bInt.Text(Base)
-	if len(id) < SLen {
-		id = strings.Repeat("0", SLen-len(id)) + id
-	}
-	return id
+	return g.Encode(Base36)
 }
 
 // Bytes returns the byte slice.
@@ -103,7 +95,7 @@ func (g *GUID) UnmarshalBinary(data []byte) error {
 		g.Reset()
 	case BLen:
 		_ = copy(g[:], data)
-	case SLen:
+	case SLen, Hex.Len():
 		return g.UnmarshalText(data)
 	default:
 		return fmt.Errorf("%s: %v", os.ErrInvalid, data)
@@ -113,10 +105,11 @@ func (g *GUID) UnmarshalBinary(data []byte) error {
 
 // MarshalText implements the encoding.TextMarshaler interface.
 func (g GUID) MarshalText() (text []byte, err error) {
-	return []byte(g.String()), nil
+	return g.AppendEncode(make([]byte, 0, SLen), Base36), nil
 }
 
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Text is parsed as base36, or as hex when it has 24 characters.
 func (g *GUID) UnmarshalText(text []byte) error {
 	switch len(text) {
 	case 0, 4:
@@ -124,13 +117,9 @@ func (g *GUID) UnmarshalText(text []byte) error {
 	case BLen:
 		return g.UnmarshalBinary(text)
 	case SLen:
-		bInt := getInt()
-		defer putInt(bInt)
-		if _, ok := bInt.SetString(string(text), Base); !ok {
-			return fmt.Errorf("%s: %v", os.ErrInvalid, text)
-		} else {
-			copy(g[:], bInt.Bytes())
-		}
+		return g.Decode(text, Base36)
+	case Hex.Len():
+		return g.Decode(text, Hex)
 	default:
 		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
 	}
@@ -139,12 +128,10 @@ func (g *GUID) UnmarshalText(text []byte) error {
 
 // MarshalJSON implements the json.Marshaler interface.
 func (g GUID) MarshalJSON() ([]byte, error) {
-	out := make([]byte, SLen+2)
+	out := make([]byte, 1, SLen+2)
 	out[0] = '"'
-	out[SLen+1] = '"'
-	buf, _ := g.MarshalText()
-	copy(out[1:], buf)
-	return out, nil
+	out = g.AppendEncode(out, Base36)
+	return append(out, '"'), nil
 }
 
 // UnmarshalJSON implements the json.Unmarshaler interface.
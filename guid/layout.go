@@ -0,0 +1,204 @@
+package guid
+
+import (
+	"fmt"
+	"github.com/azeroth-sha/simple/rand"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	MilliBLen = 12 // MilliID字节长度
+	MilliSLen = 20 // MilliID字符长度
+	WideBLen  = 16 // WideID字节长度
+	WideSLen  = 25 // WideID字符长度
+)
+
+// ID is the constraint of the IDs produced by a TypedGenerator.
+type ID interface {
+	comparable
+	fmt.Stringer
+}
+
+// TypedGenerator generates IDs of type T.
+// Generator is the TypedGenerator of the default GUID layout.
+type TypedGenerator[T ID] interface {
+	New() T
+	NewWithTime(tm time.Time) T
+}
+
+// MilliID is a GUID with millisecond precision:
+// a 6-byte millisecond timestamp, a 4-byte mark and a 2-byte serial, so 65536 IDs per millisecond.
+type MilliID [MilliBLen]byte
+
+// NewMilli returns a new MilliID.
+func NewMilli() MilliID {
+	return current.Load().milli.New()
+}
+
+// NewMilliGenerator returns a new MilliID generator.
+func NewMilliGenerator(mark uint32) TypedGenerator[MilliID] {
+	return &milliAdapter{
+		mark:   mark,
+		serial: rand.Uint32(),
+	}
+}
+
+// ParseMilli parses a MilliID from a string.
+func ParseMilli(s string) (MilliID, error) {
+	var id MilliID
+	return id, id.UnmarshalText([]byte(s))
+}
+
+// String returns the string.
+func (m MilliID) String() string {
+	return encode36(m[:], MilliSLen)
+}
+
+// Bytes returns the byte slice.
+func (m MilliID) Bytes() []byte {
+	return m[:]
+}
+
+// UnixMilli returns the timestamp in milliseconds.
+func (m MilliID) UnixMilli() int64 {
+	return int64(m[0])<<40 | int64(m[1])<<32 | int64(endian.Uint32(m[2:6]))
+}
+
+// MarkID returns the mark ID.
+func (m MilliID) MarkID() uint32 {
+	return endian.Uint32(m[6:10])
+}
+
+// Serial returns the serial number.
+func (m MilliID) Serial() uint16 {
+	return endian.Uint16(m[10:])
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (m MilliID) MarshalText() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (m *MilliID) UnmarshalText(text []byte) error {
+	return decode36(m[:], text, MilliSLen)
+}
+
+// WideID is a 16-byte GUID with a wider serial:
+// a 4-byte second timestamp, a 4-byte mark, a 4-byte serial and 4 random bytes.
+type WideID [WideBLen]byte
+
+// NewWide returns a new WideID.
+func NewWide() WideID {
+	return current.Load().wide.New()
+}
+
+// NewWideGenerator returns a new WideID generator.
+func NewWideGenerator(mark uint32) TypedGenerator[WideID] {
+	return &wideAdapter{
+		mark:   mark,
+		serial: rand.Uint32(),
+	}
+}
+
+// ParseWide parses a WideID from a string.
+func ParseWide(s string) (WideID, error) {
+	var id WideID
+	return id, id.UnmarshalText([]byte(s))
+}
+
+// String returns the string.
+func (w WideID) String() string {
+	return encode36(w[:], WideSLen)
+}
+
+// Bytes returns the byte slice.
+func (w WideID) Bytes() []byte {
+	return w[:]
+}
+
+// Unix returns the timestamp.
+func (w WideID) Unix() int64 {
+	return int64(endian.Uint32(w[:4]))
+}
+
+// MarkID returns the mark ID.
+func (w WideID) MarkID() uint32 {
+	return endian.Uint32(w[4:8])
+}
+
+// Serial returns the serial number.
+func (w WideID) Serial() uint32 {
+	return endian.Uint32(w[8:12])
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (w WideID) MarshalText() ([]byte, error) {
+	return []byte(w.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (w *WideID) UnmarshalText(text []byte) error {
+	return decode36(w[:], text, WideSLen)
+}
+
+/*
+  Package Private functions
+*/
+
+type milliAdapter struct {
+	mark   uint32
+	serial uint32
+}
+
+func (a *milliAdapter) New() MilliID {
+	return a.NewWithTime(time.Now())
+}
+
+func (a *milliAdapter) NewWithTime(tm time.Time) (id MilliID) {
+	ms := uint64(tm.UnixMilli())
+	id[0], id[1] = byte(ms>>40), byte(ms>>32)
+	endian.PutUint32(id[2:6], uint32(ms))
+	endian.PutUint32(id[6:10], a.mark)
+	endian.PutUint16(id[10:], uint16(atomic.AddUint32(&a.serial, 1)))
+	return id
+}
+
+type wideAdapter struct {
+	mark   uint32
+	serial uint32
+}
+
+func (a *wideAdapter) New() WideID {
+	return a.NewWithTime(time.Now())
+}
+
+func (a *wideAdapter) NewWithTime(tm time.Time) (id WideID) {
+	endian.PutUint32(id[:4], uint32(tm.Unix()))
+	endian.PutUint32(id[4:8], a.mark)
+	endian.PutUint32(id[8:12], atomic.AddUint32(&a.serial, 1))
+	endian.PutUint32(id[12:], rand.Uint32())
+	return id
+}
+
+// encode36 returns b in base36, zero-padded to n characters.
+func encode36(b []byte, n int) string {
+	buf := make([]byte, n)
+	encodeTo(buf, b, &alphabets[Base36])
+	return string(buf)
+}
+
+// decode36 parses the base36 text of n characters into b, empty text resets b.
+func decode36(b []byte, text []byte, n int) error {
+	switch len(text) {
+	case 0:
+		clear(b)
+		return nil
+	case n:
+	default:
+		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+	}
+	return decodeTo(b, text, &alphabets[Base36])
+}
@@ -0,0 +1,112 @@
+package guid_test
+
+import (
+	"bytes"
+	"github.com/azeroth-sha/simple/guid"
+	"testing"
+	"time"
+)
+
+func TestUUID(t *testing.T) {
+	g := guid.NewUUIDGenerator()
+	tm := time.UnixMilli(1700000000123)
+	prev := g.NewWithTime(tm)
+	for i := 0; i < 1<<12; i++ { // 用尽一毫秒的计数器后借用下一毫秒
+		id := g.NewWithTime(tm)
+		if id.Version() != 7 || id[8]&0xc0 != 0x80 {
+			t.Fatalf("%s: version %d, variant %#x", id, id.Version(), id[8]>>6)
+		}
+		if bytes.Compare(id[:], prev[:]) <= 0 {
+			t.Fatalf("%d: %s not after %s", i, id, prev)
+		}
+		prev = id
+	}
+	if prev.UnixMilli() != tm.UnixMilli()+1 {
+		t.Errorf("unix milli %d, want borrowed millisecond %d", prev.UnixMilli(), tm.UnixMilli()+1)
+	}
+	if back := g.NewWithTime(tm.Add(-time.Second)); bytes.Compare(back[:], prev[:]) <= 0 {
+		t.Errorf("%s not after %s when the clock steps back", back, prev)
+	}
+	s := prev.String()
+	if len(s) != guid.UUIDSLen || s[14] != '7' {
+		t.Errorf("string %s", s)
+	}
+	if id, err := guid.ParseUUID(s); err != nil || id != prev {
+		t.Errorf("parse %s = %s, %v", s, id, err)
+	}
+	for _, s := range []string{"0", s[:35] + "x", s[:8] + "x" + s[9:]} {
+		if _, err := guid.ParseUUID(s); err == nil {
+			t.Errorf("parsed invalid %s", s)
+		}
+	}
+}
+
+func TestSnowflake(t *testing.T) {
+	if _, err := guid.NewSnowflakeGenerator(guid.SnowflakeMaxNode + 1); err == nil {
+		t.Error("node out of range accepted")
+	}
+	g, err := guid.NewSnowflakeGenerator(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tm := guid.SnowflakeEpoch.Add(time.Hour + 7*time.Millisecond)
+	first := g.NewWithTime(tm)
+	if first.Node() != 5 || first.Seq() != 0 || !first.Time().Equal(tm) {
+		t.Fatalf("node %d, seq %d, time %s", first.Node(), first.Seq(), first.Time())
+	}
+	prev := first
+	for i := 0; i < 1<<12; i++ { // 用尽一毫秒的序列号后借用下一毫秒
+		id := g.NewWithTime(tm)
+		if id <= prev || id.Node() != 5 {
+			t.Fatalf("%d: %d not after %d, node %d", i, id, prev, id.Node())
+		}
+		prev = id
+	}
+	if prev.Seq() != 0 || !prev.Time().Equal(tm.Add(time.Millisecond)) {
+		t.Errorf("seq %d, time %s, want 0 and borrowed millisecond %s", prev.Seq(), prev.Time(), tm.Add(time.Millisecond))
+	}
+	if id, err := guid.ParseSnowflake(prev.String()); err != nil || id != prev {
+		t.Errorf("parse %s = %d, %v", prev, id, err)
+	}
+	if _, err := guid.ParseSnowflake("-1"); err == nil {
+		t.Error("parsed negative snowflake")
+	}
+}
+
+func TestMilliID(t *testing.T) {
+	tm := time.UnixMilli(1700000000123)
+	g := guid.NewMilliGenerator(0xabcdef)
+	a, b := g.NewWithTime(tm), g.NewWithTime(tm)
+	if a.UnixMilli() != tm.UnixMilli() || a.MarkID() != 0xabcdef || b.Serial() != a.Serial()+1 {
+		t.Errorf("unix milli %d, mark %#x, serials %d and %d", a.UnixMilli(), a.MarkID(), a.Serial(), b.Serial())
+	}
+	s := a.String()
+	if len(s) != guid.MilliSLen {
+		t.Errorf("string %s has %d characters, want %d", s, len(s), guid.MilliSLen)
+	}
+	if id, err := guid.ParseMilli(s); err != nil || id != a {
+		t.Errorf("parse %s = %s, %v", s, id, err)
+	}
+	if _, err := guid.ParseMilli(s[1:]); err == nil {
+		t.Errorf("parsed short %s", s[1:])
+	}
+}
+
+func TestWideID(t *testing.T) {
+	tm := time.Unix(1700000000, 0)
+	g := guid.NewWideGenerator(0xabcdef)
+	a, b := g.NewWithTime(tm), g.NewWithTime(tm)
+	if a.Unix() != tm.Unix() || a.MarkID() != 0xabcdef || b.Serial() != a.Serial()+1 {
+		t.Errorf("unix %d, mark %#x, serials %d and %d", a.Unix(), a.MarkID(), a.Serial(), b.Serial())
+	}
+	s := a.String()
+	if len(s) != guid.WideSLen {
+		t.Errorf("string %s has %d characters, want %d", s, len(s), guid.WideSLen)
+	}
+	if id, err := guid.ParseWide(s); err != nil || id != a {
+		t.Errorf("parse %s = %s, %v", s, id, err)
+	}
+	if _, err := guid.ParseWide(s + "0"); err == nil {
+		t.Errorf("parsed long %s0", s)
+	}
+}
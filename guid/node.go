@@ -0,0 +1,166 @@
+package guid
+
+import (
+	"errors"
+	"fmt"
+	"github.com/azeroth-sha/simple/lock"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+)
+
+const (
+	MaxNode = SnowflakeMaxNode // 节点ID上限，同一节点ID适用于所有布局
+	EnvNode = `GUID_NODE`      // 默认读取节点ID的环境变量
+)
+
+// 节点ID错误定义
+var (
+	ErrNode   = errors.New(`guid: node out of range`) // 节点ID超出范围
+	ErrNoNode = errors.New(`guid: no node available`) // 没有可用的节点ID
+)
+
+// MarkSource is where the mark in use comes from.
+type MarkSource string
+
+const (
+	SourceHost   MarkSource = "host"   // derived from the host ID and the PID
+	SourceEnv    MarkSource = "env"    // node ID from an environment variable
+	SourceConfig MarkSource = "config" // node ID set by SetNode
+	SourceLease  MarkSource = "lease"  // node ID leased by LeaseNode
+)
+
+// Mark returns the mark used by the default generators.
+func Mark() uint32 {
+	return current.Load().mark
+}
+
+// Source returns where the mark used by the default generators comes from.
+func Source() MarkSource {
+	return current.Load().source
+}
+
+// SetNode sets the node ID of the default generators, node must not exceed MaxNode.
+// The node ID becomes the mark of GUID, MilliID and WideID, and the node of Snowflake.
+// It replaces a generator set by SetGenerator.
+func SetNode(node uint32) error {
+	return setNode(node, SourceConfig)
+}
+
+// NodeErr returns why EnvNode could not be applied on initialization, nil if it is unset or valid.
+// The default generators then use the host mark, so services that rely on EnvNode should check it on startup.
+func NodeErr() error {
+	return envErr
+}
+
+// SetNodeFromEnv sets the node ID of the default generators from the environment variable key, EnvNode if empty.
+// The package applies EnvNode on initialization; an invalid value leaves the host mark in use and is reported by NodeErr.
+func SetNodeFromEnv(key string) error {
+	if key == "" {
+		key = EnvNode
+	}
+	s, ok := os.LookupEnv(key)
+	if !ok {
+		return fmt.Errorf("%w: %s not set", ErrNoNode, key)
+	}
+	node, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return fmt.Errorf("%w: %s=%s", ErrNode, key, s)
+	}
+	return setNode(uint32(node), SourceEnv)
+}
+
+// LeaseNode leases the lowest node ID not held by another process, coordinated by lock files in dir,
+// and sets it as the node ID of the default generators.
+// The lease lasts until Release or the exit of the process; IDs generated after Release may collide with the next holder.
+func LeaseNode(dir string) (*Lease, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	for node := uint32(0); node <= MaxNode; node++ {
+		fl := lock.NewFileLock(filepath.Join(dir, fmt.Sprintf("node-%d.lock", node)))
+		ok, err := fl.TryLockErr()
+		if err != nil {
+			return nil, err
+		} else if !ok {
+			continue // 已被其他进程持有
+		}
+		if err := setNode(node, SourceLease); err != nil {
+			fl.Unlock()
+			return nil, err
+		}
+		return &Lease{node: node, fl: fl}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrNoNode, dir)
+}
+
+// Lease is a node ID held through a lock file.
+type Lease struct {
+	node uint32
+	fl   *lock.FileLock
+}
+
+// Node returns the leased node ID.
+func (l *Lease) Node() uint32 {
+	return l.node
+}
+
+// Release releases the lease so that another process can take the node ID.
+func (l *Lease) Release() {
+	l.fl.Unlock()
+}
+
+/*
+  Package Private functions
+*/
+
+// defaults holds the mark and the default generators.
+type defaults struct {
+	mark   uint32
+	source MarkSource
+	guid   Generator
+	milli  TypedGenerator[MilliID]
+	wide   TypedGenerator[WideID]
+	uuid   TypedGenerator[UUID]
+	snow   TypedGenerator[Snowflake]
+}
+
+var current atomic.Pointer[defaults]
+
+// envErr is the error of applying EnvNode on initialization, written only by init.
+var envErr error
+
+// setNode validates node and uses it as the mark.
+func setNode(node uint32, source MarkSource) error {
+	if node > MaxNode {
+		return fmt.Errorf("%w: %d > %d", ErrNode, node, MaxNode)
+	}
+	useMark(node, uint16(node), source)
+	return nil
+}
+
+// useMark replaces the default generators, the UUID generator is kept to stay monotonic.
+// It swaps like SetGenerator, so concurrent calls of both never lose an update.
+func useMark(mark uint32, node uint16, source MarkSource) {
+	snow, _ := NewSnowflakeGenerator(node)
+	d := &defaults{
+		mark:   mark,
+		source: source,
+		guid:   NewGenerator(mark),
+		milli:  NewMilliGenerator(mark),
+		wide:   NewWideGenerator(mark),
+		snow:   snow,
+	}
+	for {
+		old := current.Load()
+		if old != nil {
+			d.uuid = old.uuid
+		} else {
+			d.uuid = NewUUIDGenerator()
+		}
+		if current.CompareAndSwap(old, d) {
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package guid_test
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple/guid"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestLeaseNode(t *testing.T) {
+	dir := t.TempDir()
+	a, err := guid.LeaseNode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := guid.LeaseNode(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a.Node() != 0 || b.Node() != 1 {
+		t.Errorf("leased %d and %d, want 0 and 1", a.Node(), b.Node())
+	}
+	if guid.Source() != guid.SourceLease || guid.Mark() != b.Node() {
+		t.Errorf("default mark %d from %s, want %d from %s", guid.Mark(), guid.Source(), b.Node(), guid.SourceLease)
+	}
+	a.Release()
+	if c, err := guid.LeaseNode(dir); err != nil || c.Node() != 0 {
+		t.Errorf("lease after release: %v, %v", c, err)
+	} else {
+		c.Release()
+	}
+	b.Release()
+}
+
+func TestLeaseNodeError(t *testing.T) {
+	dir := t.TempDir()
+	// 无法打开的锁文件不能被当作已被占用而跳过
+	if err := os.Mkdir(filepath.Join(dir, "node-0.lock"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if l, err := guid.LeaseNode(dir); err == nil {
+		t.Errorf("leased node %d past a broken lock file", l.Node())
+		l.Release()
+	}
+}
+
+const envWantNode = `GUID_TEST_WANT_NODE`
+
+func TestEnvNode(t *testing.T) {
+	if want, ok := os.LookupEnv(envWantNode); ok {
+		envNodeChild(t, want)
+		return
+	}
+	for _, c := range []struct {
+		value string
+		want  string // 期望的节点ID，为空表示退回主机标识
+	}{
+		{"7", "7"},
+		{"abc", ""},
+		{"4096", ""},
+	} {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestEnvNode$")
+		cmd.Env = append(os.Environ(), guid.EnvNode+"="+c.value, envWantNode+"="+c.want)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Errorf("%s=%s: %v\n%s", guid.EnvNode, c.value, err, out)
+		}
+	}
+}
+
+// envNodeChild checks the node applied on initialization in a process started by TestEnvNode.
+func envNodeChild(t *testing.T, want string) {
+	if want == "" {
+		if guid.Source() != guid.SourceHost || !errors.Is(guid.NodeErr(), guid.ErrNode) {
+			t.Errorf("source %s, error %v, want %s and %v", guid.Source(), guid.NodeErr(), guid.SourceHost, guid.ErrNode)
+		}
+		return
+	}
+	if err := guid.NodeErr(); err != nil {
+		t.Fatal(err)
+	}
+	if guid.Source() != guid.SourceEnv || strconv.FormatUint(uint64(guid.Mark()), 10) != want {
+		t.Errorf("mark %d from %s, want %s from %s", guid.Mark(), guid.Source(), want, guid.SourceEnv)
+	}
+}
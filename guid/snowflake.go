@@ -0,0 +1,117 @@
+package guid
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	SnowflakeNodeBits = 10                       // Snowflake节点位数
+	SnowflakeSeqBits  = 12                       // Snowflake序列号位数
+	SnowflakeMaxNode  = 1<<SnowflakeNodeBits - 1 // Snowflake最大节点
+	snowflakeMaxSeq   = 1<<SnowflakeSeqBits - 1  // Snowflake最大序列号
+	snowflakeTimeLeft = SnowflakeNodeBits + SnowflakeSeqBits
+)
+
+// SnowflakeEpoch is the epoch of Snowflake timestamps, 2020-01-01 UTC.
+var SnowflakeEpoch = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// Snowflake is a Snowflake style 64-bit ID:
+// a 41-bit millisecond timestamp since SnowflakeEpoch, a 10-bit node and a 12-bit sequence.
+type Snowflake int64
+
+// NewSnowflake returns a new Snowflake.
+// Without a node ID from GUID_NODE, SetNode or LeaseNode, the node is a 10-bit hash of the host ID and the PID,
+// which distinct processes may share; set a node ID when Snowflakes must be unique across processes.
+func NewSnowflake() Snowflake {
+	return current.Load().snow.New()
+}
+
+// NewSnowflakeGenerator returns a new Snowflake generator for node, which must not exceed SnowflakeMaxNode.
+// IDs of one generator are strictly increasing, a full sequence borrows the next millisecond.
+func NewSnowflakeGenerator(node uint16) (TypedGenerator[Snowflake], error) {
+	if node > SnowflakeMaxNode {
+		return nil, fmt.Errorf("%s: node %d out of range", os.ErrInvalid, node)
+	}
+	return &snowflakeAdapter{node: int64(node)}, nil
+}
+
+// ParseSnowflake parses a Snowflake from a decimal string.
+func ParseSnowflake(s string) (Snowflake, error) {
+	var id Snowflake
+	return id, id.UnmarshalText([]byte(s))
+}
+
+// String returns the decimal string.
+func (s Snowflake) String() string {
+	return strconv.FormatInt(int64(s), 10)
+}
+
+// Int64 returns the ID as int64.
+func (s Snowflake) Int64() int64 {
+	return int64(s)
+}
+
+// Time returns the timestamp.
+func (s Snowflake) Time() time.Time {
+	return SnowflakeEpoch.Add(time.Duration(int64(s)>>snowflakeTimeLeft) * time.Millisecond)
+}
+
+// Node returns the node.
+func (s Snowflake) Node() uint16 {
+	return uint16(int64(s) >> SnowflakeSeqBits & SnowflakeMaxNode)
+}
+
+// Seq returns the sequence number.
+func (s Snowflake) Seq() uint16 {
+	return uint16(int64(s) & snowflakeMaxSeq)
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (s Snowflake) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (s *Snowflake) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*s = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(string(text), 10, 64)
+	if err != nil || n < 0 {
+		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+	}
+	*s = Snowflake(n)
+	return nil
+}
+
+/*
+  Package Private functions
+*/
+
+type snowflakeAdapter struct {
+	mu   sync.Mutex
+	node int64
+	last int64
+	seq  int64
+}
+
+func (a *snowflakeAdapter) New() Snowflake {
+	return a.NewWithTime(time.Now())
+}
+
+func (a *snowflakeAdapter) NewWithTime(tm time.Time) Snowflake {
+	ms := tm.Sub(SnowflakeEpoch).Milliseconds()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ms > a.last {
+		a.last, a.seq = ms, 0
+	} else if a.seq++; a.seq > snowflakeMaxSeq {
+		a.last, a.seq = a.last+1, 0
+	}
+	return Snowflake(a.last<<snowflakeTimeLeft | a.node<<SnowflakeSeqBits | a.seq)
+}
@@ -0,0 +1,113 @@
+package guid
+
+import (
+	"github.com/azeroth-sha/simple/rand"
+	"sync"
+	"time"
+)
+
+const maxSerial = 1 << 16 // 每秒的序列号数量
+
+// StrictOption configures a StrictGenerator.
+type StrictOption func(*StrictGenerator)
+
+// WithWait makes the generator wait for the next second when the serials of the current second are used up,
+// instead of borrowing the next second ahead of the clock.
+func WithWait() StrictOption {
+	return func(g *StrictGenerator) {
+		g.wait = true
+	}
+}
+
+// WithBackwards sets a function called with the latest and the current unix second whenever the clock moves backwards.
+// It is called without holding the generator, so it may log or record metrics.
+func WithBackwards(f func(last, now int64)) StrictOption {
+	return func(g *StrictGenerator) {
+		g.onBackwards = f
+	}
+}
+
+// NewStrictGenerator returns a generator of strictly increasing GUIDs.
+// Serials restart from zero every second, so each second holds 65536 IDs; when they are used up
+// the generator borrows the next second, or waits for it with WithWait.
+// When the clock moves backwards the generator keeps counting in the last second it used.
+func NewStrictGenerator(mark uint32, opts ...StrictOption) *StrictGenerator {
+	g := &StrictGenerator{mark: mark}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// StrictGenerator generates GUIDs that are strictly increasing and unique within the process.
+type StrictGenerator struct {
+	mu          sync.Mutex
+	mark        uint32
+	wait        bool
+	clock       int64  // latest unix second seen from the clock
+	last        int64  // unix second of the last ID, ahead of clock after borrowing
+	serial      uint32 // next serial within last
+	backwards   uint64
+	onBackwards func(last, now int64)
+}
+
+func (g *StrictGenerator) New() GUID {
+	return g.NewWithTime(time.Now())
+}
+
+func (g *StrictGenerator) NewWithTime(tm time.Time) (id GUID) {
+	sec, serial, clock, back := g.next(tm.Unix())
+	if back >= 0 && g.onBackwards != nil {
+		g.onBackwards(clock, back)
+	}
+	endian.PutUint32(id[:4], uint32(sec))
+	endian.PutUint32(id[4:8], g.mark)
+	endian.PutUint16(id[8:10], uint16(serial))
+	endian.PutUint16(id[10:], rand.Uint16())
+	return id
+}
+
+// Backwards returns the number of times the clock was seen moving backwards.
+func (g *StrictGenerator) Backwards() uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.backwards
+}
+
+/*
+  Package Private functions
+*/
+
+// next returns the second and serial of the next ID,
+// and when the clock moved backwards the latest and the current clock second, otherwise back is -1.
+func (g *StrictGenerator) next(sec int64) (int64, uint32, int64, int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	back := int64(-1)
+	if sec < g.clock {
+		g.backwards++
+		back = sec
+	} else {
+		g.clock = sec
+	}
+	if sec > g.last {
+		g.last, g.serial = sec, 0
+	}
+	if g.serial >= maxSerial {
+		if g.wait {
+			g.sleep()
+		}
+		g.last, g.serial = g.last+1, 0
+	}
+	serial := g.serial
+	g.serial++
+	return g.last, serial, g.clock, back
+}
+
+// sleep waits until the clock reaches the second after last, other callers wait on mu meanwhile.
+func (g *StrictGenerator) sleep() {
+	next := time.Unix(g.last+1, 0)
+	if d := time.Until(next); d > 0 {
+		time.Sleep(d)
+	}
+}
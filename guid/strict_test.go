@@ -0,0 +1,80 @@
+package guid_test
+
+import (
+	"github.com/azeroth-sha/simple/guid"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestStrictMonotonic(t *testing.T) {
+	g := guid.NewStrictGenerator(1)
+	tm := time.Unix(1700000000, 0)
+	prev := g.NewWithTime(tm)
+	for i := 0; i < 1<<16; i++ { // 用尽一秒的序列号后借用下一秒
+		id := g.NewWithTime(tm)
+		if !id.Gt(prev) {
+			t.Fatalf("%d: %s not after %s", i, id, prev)
+		}
+		prev = id
+	}
+	if prev.Unix() != tm.Unix()+1 {
+		t.Errorf("unix %d, want borrowed second %d", prev.Unix(), tm.Unix()+1)
+	}
+	if n := g.Backwards(); n != 0 {
+		t.Errorf("%d backwards jumps on a steady clock", n)
+	}
+}
+
+func TestStrictConcurrent(t *testing.T) {
+	g := guid.NewStrictGenerator(1)
+	var (
+		mu   sync.Mutex
+		seen = make(map[guid.GUID]struct{})
+		wg   sync.WaitGroup
+	)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			prev := guid.NULL
+			for j := 0; j < 1000; j++ {
+				id := g.New()
+				if !id.Gt(prev) {
+					t.Errorf("%s not after %s", id, prev)
+					return
+				}
+				prev = id
+				mu.Lock()
+				seen[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if len(seen) != 8*1000 {
+		t.Errorf("%d unique IDs, want %d", len(seen), 8*1000)
+	}
+}
+
+func TestStrictBackwards(t *testing.T) {
+	var last, now int64
+	g := guid.NewStrictGenerator(1, guid.WithBackwards(func(l, n int64) {
+		last, now = l, n
+	}))
+	tm := time.Unix(1700000000, 0)
+	prev := g.NewWithTime(tm)
+	id := g.NewWithTime(tm.Add(-time.Second * 5)) // 时钟回拨
+	if !id.Gt(prev) {
+		t.Errorf("%s not after %s when the clock moved backwards", id, prev)
+	}
+	if id.Unix() != tm.Unix() {
+		t.Errorf("unix %d, want last second %d", id.Unix(), tm.Unix())
+	}
+	if g.Backwards() != 1 || last != tm.Unix() || now != tm.Unix()-5 {
+		t.Errorf("backwards %d (%d -> %d), want 1 (%d -> %d)", g.Backwards(), last, now, tm.Unix(), tm.Unix()-5)
+	}
+	if next := g.NewWithTime(tm.Add(time.Second)); !next.Gt(id) || g.Backwards() != 1 {
+		t.Errorf("clock recovery: %s after %s, backwards %d", next, id, g.Backwards())
+	}
+}
@@ -0,0 +1,129 @@
+package guid
+
+import (
+	"encoding/hex"
+	"fmt"
+	"github.com/azeroth-sha/simple/rand"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	UUIDBLen = 16 // UUID字节长度
+	UUIDSLen = 36 // UUID字符长度
+)
+
+// UUID is a RFC 9562 version 7 UUID:
+// a 48-bit millisecond timestamp, a 12-bit counter for IDs within the same millisecond and 62 random bits.
+type UUID [UUIDBLen]byte
+
+// NewUUID returns a new version 7 UUID.
+func NewUUID() UUID {
+	return current.Load().uuid.New()
+}
+
+// NewUUIDGenerator returns a new version 7 UUID generator.
+// IDs of one generator are strictly increasing, a full counter borrows the next millisecond.
+func NewUUIDGenerator() TypedGenerator[UUID] {
+	return new(uuidAdapter)
+}
+
+// ParseUUID parses a UUID in the canonical 8-4-4-4-12 form.
+func ParseUUID(s string) (UUID, error) {
+	var id UUID
+	return id, id.UnmarshalText([]byte(s))
+}
+
+// String returns the canonical 8-4-4-4-12 form.
+func (u UUID) String() string {
+	buf := make([]byte, UUIDSLen)
+	hex.Encode(buf[0:8], u[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], u[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], u[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], u[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], u[10:])
+	return string(buf)
+}
+
+// Bytes returns the byte slice.
+func (u UUID) Bytes() []byte {
+	return u[:]
+}
+
+// Version returns the version number.
+func (u UUID) Version() int {
+	return int(u[6] >> 4)
+}
+
+// UnixMilli returns the timestamp in milliseconds.
+func (u UUID) UnixMilli() int64 {
+	return int64(u[0])<<40 | int64(u[1])<<32 | int64(endian.Uint32(u[2:6]))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (u UUID) MarshalText() ([]byte, error) {
+	return []byte(u.String()), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+func (u *UUID) UnmarshalText(text []byte) error {
+	if len(text) == 0 {
+		*u = UUID{}
+		return nil
+	}
+	if len(text) != UUIDSLen || text[8] != '-' || text[13] != '-' || text[18] != '-' || text[23] != '-' {
+		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+	}
+	var digits [UUIDBLen * 2]byte
+	n := copy(digits[:], text[0:8])
+	n += copy(digits[n:], text[9:13])
+	n += copy(digits[n:], text[14:18])
+	n += copy(digits[n:], text[19:23])
+	copy(digits[n:], text[24:])
+	var id UUID
+	if _, err := hex.Decode(id[:], digits[:]); err != nil {
+		return fmt.Errorf("%s: %s", os.ErrInvalid, text)
+	}
+	*u = id
+	return nil
+}
+
+/*
+  Package Private functions
+*/
+
+type uuidAdapter struct {
+	mu      sync.Mutex
+	last    int64
+	counter uint16
+}
+
+func (a *uuidAdapter) New() UUID {
+	return a.NewWithTime(time.Now())
+}
+
+func (a *uuidAdapter) NewWithTime(tm time.Time) (id UUID) {
+	ms, counter := a.next(tm.UnixMilli())
+	id[0], id[1] = byte(ms>>40), byte(ms>>32)
+	endian.PutUint32(id[2:6], uint32(ms))
+	endian.PutUint16(id[6:8], 0x7000|counter)
+	endian.PutUint64(id[8:], rand.Uint64()&0x3fffffffffffffff|0x8000000000000000)
+	return id
+}
+
+// next returns the timestamp and counter of the next ID, keeping them increasing when the clock stalls or steps back.
+func (a *uuidAdapter) next(ms int64) (int64, uint16) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if ms > a.last {
+		a.last, a.counter = ms, 0
+	} else if a.counter++; a.counter > 0xfff {
+		a.last, a.counter = a.last+1, 0
+	}
+	return a.last, a.counter
+}
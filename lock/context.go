@@ -0,0 +1,273 @@
+package lock
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// ContextMutex is a Mutex whose acquisition can be abandoned.
+type ContextMutex interface {
+	Mutex
+	// LockContext locks the mutex, or returns ctx.Err() if ctx is done first.
+	LockContext(ctx context.Context) error
+	// TryLockFor tries to lock the mutex within d and reports whether it succeeded.
+	TryLockFor(d time.Duration) bool
+}
+
+// ContextRWMutex is a RWMutex whose acquisition can be abandoned.
+type ContextRWMutex interface {
+	RWMutex
+	LockContext(ctx context.Context) error
+	TryLockFor(d time.Duration) bool
+	// RLockContext locks the mutex for reading, or returns ctx.Err() if ctx is done first.
+	RLockContext(ctx context.Context) error
+	// TryRLockFor tries to lock the mutex for reading within d and reports whether it succeeded.
+	TryRLockFor(d time.Duration) bool
+}
+
+// NewMutex returns a channel based mutex.
+func NewMutex() ContextMutex {
+	return &chanMutex{ch: make(chan struct{}, 1)}
+}
+
+// NewRWMutex returns a rw mutex that prefers waiting writers over new readers.
+func NewRWMutex() ContextRWMutex {
+	return new(waitRWMutex)
+}
+
+/*
+	Package method
+*/
+
+// chanMutex holds the lock while its channel is full.
+type chanMutex struct {
+	ch chan struct{}
+}
+
+func (m *chanMutex) Lock() {
+	m.ch <- struct{}{}
+}
+
+func (m *chanMutex) Unlock() {
+	select {
+	case <-m.ch:
+	default:
+		panic("lock: unlock of unlocked mutex")
+	}
+}
+
+func (m *chanMutex) TryLock() bool {
+	select {
+	case m.ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (m *chanMutex) LockContext(ctx context.Context) error {
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	default:
+	}
+	select {
+	case m.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (m *chanMutex) TryLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.LockContext(ctx) == nil
+}
+
+// waitRWMutex hands the lock over to its waiters directly.
+// New readers wait while a writer holds or waits for the lock; when a writer releases the lock,
+// the readers that waited for it go first, otherwise the lock passes to the writers one at a time in arrival order.
+type waitRWMutex struct {
+	mu      sync.Mutex
+	readers int           // readers holding the lock
+	writer  bool          // a writer holds the lock
+	rwait   int           // readers waiting for the lock
+	rready  chan struct{} // closed to grant the waiting readers, nil when none has waited
+	writers list.List     // waiting writers in arrival order
+}
+
+// rwWaiter is a writer waiting for the lock, ready is closed once the lock is handed to it.
+type rwWaiter struct {
+	ready chan struct{}
+}
+
+func (m *waitRWMutex) Lock() {
+	_ = m.lock(context.Background(), false)
+}
+
+func (m *waitRWMutex) Unlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.writer {
+		panic("lock: unlock of unlocked mutex")
+	}
+	m.unlock()
+}
+
+func (m *waitRWMutex) TryLock() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.grant(false)
+}
+
+func (m *waitRWMutex) LockContext(ctx context.Context) error {
+	return m.lock(ctx, false)
+}
+
+func (m *waitRWMutex) TryLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.lock(ctx, false) == nil
+}
+
+func (m *waitRWMutex) RLock() {
+	_ = m.lock(context.Background(), true)
+}
+
+func (m *waitRWMutex) RUnlock() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.readers <= 0 {
+		panic("lock: runlock of unlocked mutex")
+	}
+	m.runlock()
+}
+
+func (m *waitRWMutex) TryRLock() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.grant(true)
+}
+
+func (m *waitRWMutex) RLockContext(ctx context.Context) error {
+	return m.lock(ctx, true)
+}
+
+func (m *waitRWMutex) TryRLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.lock(ctx, true) == nil
+}
+
+// lock waits until the lock is handed over or ctx is done.
+func (m *waitRWMutex) lock(ctx context.Context, read bool) error {
+	m.mu.Lock()
+	if m.grant(read) {
+		m.mu.Unlock()
+		return nil
+	}
+	if read {
+		return m.waitRead(ctx)
+	}
+	return m.waitWrite(ctx)
+}
+
+// waitRead waits as a reader, must be called with mu held and releases it.
+func (m *waitRWMutex) waitRead(ctx context.Context) error {
+	if m.rready == nil {
+		m.rready = make(chan struct{})
+	}
+	m.rwait++
+	ready := m.rready
+	m.mu.Unlock()
+	select {
+	case <-ready:
+		return nil
+	case <-ctx.Done():
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-ready: // granted while cancelling, give it back
+		m.runlock()
+	default:
+		m.rwait--
+	}
+	return ctx.Err()
+}
+
+// waitWrite waits as a writer, must be called with mu held and releases it.
+func (m *waitRWMutex) waitWrite(ctx context.Context) error {
+	w := &rwWaiter{ready: make(chan struct{})}
+	elem := m.writers.PushBack(w)
+	m.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	select {
+	case <-w.ready: // granted while cancelling, give it back
+		m.unlock()
+	default:
+		m.writers.Remove(elem)
+		m.wake(false) // readers may have been waiting for this writer only
+	}
+	return ctx.Err()
+}
+
+// grant takes the lock if it is available and nobody waits for it, must be called with mu held.
+func (m *waitRWMutex) grant(read bool) bool {
+	if m.writer || m.writers.Len() > 0 {
+		return false
+	}
+	if read {
+		m.readers++
+		return true
+	}
+	if m.readers > 0 {
+		return false
+	}
+	m.writer = true
+	return true
+}
+
+// unlock releases the write lock, must be called with mu held.
+func (m *waitRWMutex) unlock() {
+	m.writer = false
+	m.wake(true)
+}
+
+// runlock releases a read lock, must be called with mu held.
+func (m *waitRWMutex) runlock() {
+	if m.readers--; m.readers == 0 {
+		m.wake(false)
+	}
+}
+
+// wake hands the lock to waiters, must be called with mu held.
+// After a write the waiting readers go first, so a stream of writers cannot starve them;
+// otherwise they only go when no writer is waiting.
+func (m *waitRWMutex) wake(afterWrite bool) {
+	if m.writer {
+		return
+	}
+	if m.rwait > 0 && (afterWrite || m.writers.Len() == 0) {
+		m.readers += m.rwait
+		m.rwait = 0
+		close(m.rready)
+		m.rready = nil
+		return
+	}
+	if m.readers == 0 && m.writers.Len() > 0 {
+		front := m.writers.Front()
+		m.writers.Remove(front)
+		m.writer = true
+		close(front.Value.(*rwWaiter).ready)
+	}
+}
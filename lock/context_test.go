@@ -0,0 +1,192 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple/lock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMutexLockContext(t *testing.T) {
+	m := lock.NewMutex()
+	m.Lock()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*20)
+	defer cancel()
+	if err := m.LockContext(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("lock context error %v, want %v", err, context.DeadlineExceeded)
+	}
+	m.Unlock()
+	if !m.TryLockFor(time.Second) {
+		t.Error("mutex still held after a cancelled wait")
+	}
+}
+
+func TestRWMutexCancelWriter(t *testing.T) {
+	m := lock.NewRWMutex()
+	m.RLock()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- m.LockContext(ctx) }()
+	waitFor(t, writerWaiting(m)) // wait until the writer is queued
+	read := make(chan struct{})
+	go func() {
+		m.RLock() // queued behind the writer
+		close(read)
+	}()
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("lock context error %v, want %v", err, context.Canceled)
+	}
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("reader not woken after the waiting writer gave up")
+	}
+	m.RUnlock()
+	m.RUnlock()
+	if !m.TryLock() {
+		t.Error("mutex still held after all readers left")
+	}
+}
+
+func TestRWMutexWriterPreference(t *testing.T) {
+	m := lock.NewRWMutex()
+	m.RLock()
+	locked := make(chan struct{})
+	go func() {
+		m.Lock()
+		close(locked)
+	}()
+	waitFor(t, writerWaiting(m)) // new readers are refused while the writer waits
+	read := make(chan struct{})
+	go func() {
+		m.RLock()
+		close(read)
+	}()
+	m.RUnlock()
+	select {
+	case <-locked:
+	case <-time.After(time.Second):
+		t.Fatal("writer not granted after readers left")
+	}
+	select {
+	case <-read:
+		t.Fatal("reader granted while writer holds the lock")
+	case <-time.After(time.Millisecond * 20):
+	}
+	m.Unlock()
+	select {
+	case <-read:
+	case <-time.After(time.Second):
+		t.Fatal("reader not granted after writer unlocked")
+	}
+	m.RUnlock()
+}
+
+func TestRWMutexWriterOrder(t *testing.T) {
+	m := lock.NewRWMutex()
+	m.Lock()
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			m.Lock()
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			m.Unlock()
+		}(i)
+		time.Sleep(time.Millisecond * 5) // let writer i queue before the next
+	}
+	m.Unlock()
+	wg.Wait()
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("writers granted in order %v, want arrival order", order)
+		}
+	}
+}
+
+func TestRWMutexStress(t *testing.T) {
+	m := lock.NewRWMutex()
+	var (
+		wg            sync.WaitGroup
+		value, shadow int
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 300; j++ {
+				ctx, cancel := context.WithTimeout(context.Background(), time.Duration(j%3)*time.Microsecond*50)
+				if i%2 == 0 {
+					if m.LockContext(ctx) == nil {
+						value++
+						shadow++
+						m.Unlock()
+					}
+				} else if m.RLockContext(ctx) == nil {
+					if value != shadow {
+						t.Error("reader saw a write in progress")
+					}
+					m.RUnlock()
+				}
+				cancel()
+			}
+		}(i)
+	}
+	wg.Wait()
+	if !m.TryLock() {
+		t.Error("mutex still held after all goroutines finished")
+	}
+}
+
+func BenchmarkRWMutexLock(b *testing.B) {
+	m := lock.NewRWMutex()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		m.Lock()
+		m.Unlock()
+	}
+}
+
+func BenchmarkRWMutexRLockParallel(b *testing.B) {
+	m := lock.NewRWMutex()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			m.RLock()
+			m.RUnlock()
+		}
+	})
+}
+
+// writerWaiting reports whether new readers are refused, releasing the read lock if one was granted.
+func writerWaiting(m lock.ContextRWMutex) func() bool {
+	return func() bool {
+		if m.TryRLock() {
+			m.RUnlock()
+			return false
+		}
+		return true
+	}
+}
+
+// waitFor polls cond until it holds or a second has passed.
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
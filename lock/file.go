@@ -0,0 +1,250 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrUnsupported is returned when file locks are not supported on the platform.
+var ErrUnsupported = errors.New(`lock: file lock not supported`)
+
+// NewFileLock returns a cross-process lock backed by the file at path.
+// The file is created on first use, and the holder's PID is written into it while locked.
+func NewFileLock(path string) *FileLock {
+	return &FileLock{
+		path:  path,
+		local: NewMutex(),
+	}
+}
+
+// FileLock is a cross-process exclusive lock that implements ContextMutex.
+// Goroutines of the same process sharing a FileLock also exclude each other.
+// Lock panics if the lock file cannot be opened or locked; use LockContext to get the error instead.
+type FileLock struct {
+	path  string
+	local ContextMutex
+	f     *os.File
+}
+
+// Path returns the path of the lock file.
+func (l *FileLock) Path() string {
+	return l.path
+}
+
+func (l *FileLock) Lock() {
+	l.local.Lock()
+	if _, err := l.acquire(true); err != nil {
+		l.local.Unlock()
+		panic(err)
+	}
+}
+
+func (l *FileLock) Unlock() {
+	f := l.f
+	if f == nil {
+		panic("lock: unlock of unlocked file lock")
+	}
+	l.f = nil
+	_ = f.Truncate(0)
+	_ = funlock(f)
+	_ = f.Close()
+	l.local.Unlock()
+}
+
+func (l *FileLock) TryLock() bool {
+	ok, _ := l.TryLockErr()
+	return ok
+}
+
+// TryLockErr tries to lock the file without blocking.
+// Unlike TryLock it tells contention, reported as false with a nil error, from failures to open or lock the file.
+func (l *FileLock) TryLockErr() (bool, error) {
+	if !l.local.TryLock() {
+		return false, nil
+	}
+	ok, err := l.acquire(false)
+	if !ok {
+		l.local.Unlock()
+	}
+	return ok, err
+}
+
+// LockContext locks the file, polling until it is free, or returns an error if ctx is done first.
+func (l *FileLock) LockContext(ctx context.Context) error {
+	if err := l.local.LockContext(ctx); err != nil {
+		return err
+	}
+	wait := time.Millisecond
+	for {
+		ok, err := l.acquire(false)
+		if err != nil {
+			l.local.Unlock()
+			return err
+		}
+		if ok {
+			return nil
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			l.local.Unlock()
+			return ctx.Err()
+		}
+		if wait < 100*time.Millisecond {
+			wait *= 2
+		}
+	}
+}
+
+func (l *FileLock) TryLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return l.LockContext(ctx) == nil
+}
+
+// Owner returns the PID recorded in the lock file, or 0 if the lock is not held.
+func (l *FileLock) Owner() (int, error) {
+	buf, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	s := strings.TrimSpace(string(buf))
+	if s == "" {
+		return 0, nil
+	}
+	return strconv.Atoi(s)
+}
+
+// Stale reports whether the lock file records a holder process that no longer exists.
+func (l *FileLock) Stale() (bool, error) {
+	pid, err := l.Owner()
+	if err != nil || pid == 0 {
+		return false, err
+	}
+	return !alive(pid), nil
+}
+
+// NewKeyedFileLock returns a set of file locks in dir, one lock file per key.
+func NewKeyedFileLock(dir string) (*KeyedFileLock, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &KeyedFileLock{
+		dir:   dir,
+		locks: make(map[string]*FileLock),
+	}, nil
+}
+
+// KeyedFileLock maps each key to a lock file in a directory.
+type KeyedFileLock struct {
+	dir   string
+	mu    sync.Mutex
+	locks map[string]*FileLock
+}
+
+// Get returns the file lock of key.
+func (k *KeyedFileLock) Get(key string) *FileLock {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	l, ok := k.locks[key]
+	if !ok {
+		l = NewFileLock(filepath.Join(k.dir, url.PathEscape(key)+fileLockExt))
+		k.locks[key] = l
+	}
+	return l
+}
+
+// Lock locks key.
+func (k *KeyedFileLock) Lock(key string) {
+	k.Get(key).Lock()
+}
+
+// Unlock unlocks key.
+func (k *KeyedFileLock) Unlock(key string) {
+	k.Get(key).Unlock()
+}
+
+// TryLock tries to lock key and reports whether it succeeded.
+func (k *KeyedFileLock) TryLock(key string) bool {
+	return k.Get(key).TryLock()
+}
+
+// Clean removes the lock files whose recorded holder process no longer exists, and returns the number removed.
+func (k *KeyedFileLock) Clean() (int, error) {
+	files, err := filepath.Glob(filepath.Join(k.dir, "*"+fileLockExt))
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, path := range files {
+		l := NewFileLock(path)
+		if stale, err := l.Stale(); err != nil || !stale {
+			continue
+		}
+		if !l.TryLock() {
+			continue // taken over by another process
+		}
+		if os.Remove(path) == nil {
+			n++
+		}
+		l.Unlock()
+	}
+	return n, nil
+}
+
+/*
+	Package method
+*/
+
+const fileLockExt = ".lock"
+
+// acquire opens and locks the lock file, block waits until the file is free.
+func (l *FileLock) acquire(block bool) (bool, error) {
+	for {
+		f, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0o644)
+		if err != nil {
+			return false, err
+		}
+		ok, err := flock(f, block)
+		if err != nil || !ok {
+			_ = f.Close()
+			return false, err
+		}
+		if !current(f, l.path) {
+			_ = f.Close() // removed by Clean while waiting, lock the new file instead
+			continue
+		}
+		if err = f.Truncate(0); err == nil {
+			_, err = f.WriteAt([]byte(strconv.Itoa(os.Getpid())), 0)
+		}
+		if err != nil {
+			_ = funlock(f)
+			_ = f.Close()
+			return false, err
+		}
+		l.f = f
+		return true, nil
+	}
+}
+
+// current reports whether f is still the file at path.
+func current(f *os.File, path string) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	pi, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return os.SameFile(fi, pi)
+}
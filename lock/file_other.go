@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !freebsd && !netbsd && !openbsd && !dragonfly
+// +build !linux,!darwin,!freebsd,!netbsd,!openbsd,!dragonfly
+
+package lock
+
+import (
+	"os"
+)
+
+// flock is not supported on this platform.
+func flock(*os.File, bool) (bool, error) {
+	return false, ErrUnsupported
+}
+
+// funlock is not supported on this platform.
+func funlock(*os.File) error {
+	return ErrUnsupported
+}
+
+// alive assumes the process exists, so no lock is considered stale.
+func alive(int) bool {
+	return true
+}
@@ -0,0 +1,55 @@
+//go:build linux || darwin || freebsd || netbsd || openbsd || dragonfly
+// +build linux darwin freebsd netbsd openbsd dragonfly
+
+package lock_test
+
+import (
+	"github.com/azeroth-sha/simple/lock"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestFileLockStale(t *testing.T) {
+	dir := t.TempDir()
+	k, err := lock.NewKeyedFileLock(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	l := k.Get(`live`)
+	l.Lock()
+	if pid, err := l.Owner(); err != nil || pid != os.Getpid() {
+		t.Errorf("owner %d, %v, want %d", pid, err, os.Getpid())
+	}
+	if stale, err := l.Stale(); err != nil || stale {
+		t.Errorf("held lock reported stale: %v", err)
+	}
+	if lock.NewFileLock(l.Path()).TryLock() {
+		t.Error("second file lock acquired a held file")
+	}
+
+	// a lock file left behind by a process that has exited
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err = cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	dead := filepath.Join(dir, `dead.lock`)
+	if err = os.WriteFile(dead, []byte(strconv.Itoa(cmd.Process.Pid)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if stale, err := lock.NewFileLock(dead).Stale(); err != nil || !stale {
+		t.Errorf("dead holder not stale: %v, %v", stale, err)
+	}
+	if n, err := k.Clean(); err != nil || n != 1 {
+		t.Errorf("clean removed %d, %v, want 1", n, err)
+	}
+	if _, err = os.Stat(dead); !os.IsNotExist(err) {
+		t.Errorf("stale lock file not removed: %v", err)
+	}
+	if _, err = os.Stat(l.Path()); err != nil {
+		t.Errorf("held lock file removed: %v", err)
+	}
+	l.Unlock()
+}
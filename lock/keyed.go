@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"runtime"
+	"sync"
+)
+
+// NewKeyedMutex returns a keyed mutex.
+// Each distinct key gets its own rw mutex, created on demand and freed once no goroutine holds or waits for it.
+func NewKeyedMutex() *KeyedMutex {
+	cnt := runtime.NumCPU() * 4
+	k := &KeyedMutex{
+		shards: make([]*keyedShard, cnt),
+		size:   uint32(cnt),
+	}
+	for i := 0; i < cnt; i++ {
+		k.shards[i] = &keyedShard{locks: make(map[string]*keyedLock)}
+	}
+	return k
+}
+
+// KeyedMutex is a set of rw mutexes indexed by key.
+// Unlike MutexPool, different keys never share a mutex.
+type KeyedMutex struct {
+	shards []*keyedShard
+	size   uint32
+}
+
+// Lock locks key for writing.
+func (k *KeyedMutex) Lock(key string) {
+	k.acquire(key).Lock()
+}
+
+// Unlock unlocks key for writing.
+func (k *KeyedMutex) Unlock(key string) {
+	k.held(key).Unlock()
+	k.release(key)
+}
+
+// TryLock tries to lock key for writing and reports whether it succeeded.
+func (k *KeyedMutex) TryLock(key string) bool {
+	if k.acquire(key).TryLock() {
+		return true
+	}
+	k.release(key)
+	return false
+}
+
+// RLock locks key for reading.
+func (k *KeyedMutex) RLock(key string) {
+	k.acquire(key).RLock()
+}
+
+// RUnlock unlocks key for reading.
+func (k *KeyedMutex) RUnlock(key string) {
+	k.held(key).RUnlock()
+	k.release(key)
+}
+
+// TryRLock tries to lock key for reading and reports whether it succeeded.
+func (k *KeyedMutex) TryRLock(key string) bool {
+	if k.acquire(key).TryRLock() {
+		return true
+	}
+	k.release(key)
+	return false
+}
+
+// Len returns the number of keys currently held or waited for.
+func (k *KeyedMutex) Len() int {
+	n := 0
+	for _, s := range k.shards {
+		s.mu.Lock()
+		n += len(s.locks)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+/*
+	Package method
+*/
+
+type keyedShard struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+type keyedLock struct {
+	sync.RWMutex
+	ref int
+}
+
+func (k *KeyedMutex) shard(key string) *keyedShard {
+	return k.shards[sum(key)%k.size]
+}
+
+// acquire returns the lock of key, creating it if needed, and adds a reference.
+func (k *KeyedMutex) acquire(key string) *keyedLock {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[key]
+	if !ok {
+		l = new(keyedLock)
+		s.locks[key] = l
+	}
+	l.ref++
+	return l
+}
+
+// held returns the lock of key, panics if key is not locked.
+func (k *KeyedMutex) held(key string) *keyedLock {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	l, ok := s.locks[key]
+	if !ok {
+		panic("lock: unlock of unlocked key " + key)
+	}
+	return l
+}
+
+// release drops a reference and frees the lock of key when it is no longer referenced.
+func (k *KeyedMutex) release(key string) {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if l, ok := s.locks[key]; ok {
+		if l.ref--; l.ref <= 0 {
+			delete(s.locks, key)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package lock_test
+
+import (
+	"github.com/azeroth-sha/simple/lock"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestKeyedMutexCleanup(t *testing.T) {
+	k := lock.NewKeyedMutex()
+	var (
+		wg      sync.WaitGroup
+		counter [4]int
+	)
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				n := (i + j) % len(counter)
+				key := strconv.Itoa(n)
+				switch j % 3 {
+				case 0:
+					k.Lock(key)
+					counter[n]++
+					k.Unlock(key)
+				case 1:
+					k.RLock(key)
+					_ = counter[n]
+					k.RUnlock(key)
+				default:
+					if k.TryLock(key) {
+						counter[n]++
+						k.Unlock(key)
+					}
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := k.Len(); n != 0 {
+		t.Errorf("%d keys left after all unlocks", n)
+	}
+}
+
+func TestKeyedMutexTryLock(t *testing.T) {
+	k := lock.NewKeyedMutex()
+	k.Lock(`a`)
+	if k.TryLock(`a`) || k.TryRLock(`a`) {
+		t.Error("locked key acquired twice")
+	}
+	if !k.TryLock(`b`) {
+		t.Error("distinct key is blocked")
+	}
+	if n := k.Len(); n != 2 {
+		t.Errorf("%d keys held, want 2", n)
+	}
+	k.Unlock(`a`)
+	k.Unlock(`b`)
+	if n := k.Len(); n != 0 {
+		t.Errorf("%d keys left after unlock", n)
+	}
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/azeroth-sha/simple/internal"
 	"hash/fnv"
 	"runtime"
-	"sync"
 )
 
 type Mutex interface {
@@ -21,33 +20,35 @@ type RWMutex interface {
 }
 
 // NewMutexPool returns a mutex pool.
+// The mutexes of the pool implement ContextMutex.
 func NewMutexPool(n ...int) *MutexPool {
 	cnt := runtime.NumCPU() * 4
 	if len(n) > 0 && n[0] > 0 {
 		cnt = n[0]
 	}
 	p := &MutexPool{
-		pool: make([]Mutex, cnt),
+		pool: make([]ContextMutex, cnt),
 		size: uint32(cnt),
 	}
 	for i := 0; i < cnt; i++ {
-		p.pool[i] = new(sync.Mutex)
+		p.pool[i] = NewMutex()
 	}
 	return p
 }
 
 // NewRWMutexPool returns a rw mutex pool.
+// The mutexes of the pool implement ContextRWMutex.
 func NewRWMutexPool(n ...int) *RwMutexPool {
 	cnt := runtime.NumCPU() * 4
 	if len(n) > 0 && n[0] > 0 {
 		cnt = n[0]
 	}
 	p := &RwMutexPool{
-		pool: make([]RWMutex, cnt),
+		pool: make([]ContextRWMutex, cnt),
 		size: uint32(cnt),
 	}
 	for i := 0; i < cnt; i++ {
-		p.pool[i] = new(sync.RWMutex)
+		p.pool[i] = NewRWMutex()
 	}
 	return p
 }
@@ -57,20 +58,22 @@ func NewRWMutexPool(n ...int) *RwMutexPool {
 */
 
 type MutexPool struct {
-	pool []Mutex
+	pool []ContextMutex
 	size uint32
+	in   *instrument
 }
 
-func (m *MutexPool) Get(s string) Mutex {
+func (m *MutexPool) Get(s string) ContextMutex {
 	return m.pool[sum(s)%m.size]
 }
 
 type RwMutexPool struct {
-	pool []RWMutex
+	pool []ContextRWMutex
 	size uint32
+	in   *instrument
 }
 
-func (m *RwMutexPool) Get(s string) RWMutex {
+func (m *RwMutexPool) Get(s string) ContextRWMutex {
 	return m.pool[sum(s)%m.size]
 }
 
@@ -0,0 +1,77 @@
+package lock
+
+import (
+	"sort"
+)
+
+// LockAll locks the mutexes of all keys and returns a function that unlocks them.
+// Keys sharing a mutex lock it only once, and mutexes are always locked in slot order,
+// so concurrent LockAll calls with overlapping keys cannot deadlock.
+func (m *MutexPool) LockAll(keys ...string) (unlock func()) {
+	slots := m.slots(keys)
+	for _, i := range slots {
+		m.pool[i].Lock()
+	}
+	return func() {
+		for j := len(slots) - 1; j >= 0; j-- {
+			m.pool[slots[j]].Unlock()
+		}
+	}
+}
+
+// LockAll locks the mutexes of all keys for writing and returns a function that unlocks them.
+// See MutexPool.LockAll for the ordering guarantee.
+func (m *RwMutexPool) LockAll(keys ...string) (unlock func()) {
+	slots := m.slots(keys)
+	for _, i := range slots {
+		m.pool[i].Lock()
+	}
+	return func() {
+		for j := len(slots) - 1; j >= 0; j-- {
+			m.pool[slots[j]].Unlock()
+		}
+	}
+}
+
+// RLockAll locks the mutexes of all keys for reading and returns a function that unlocks them.
+// See MutexPool.LockAll for the ordering guarantee.
+func (m *RwMutexPool) RLockAll(keys ...string) (unlock func()) {
+	slots := m.slots(keys)
+	for _, i := range slots {
+		m.pool[i].RLock()
+	}
+	return func() {
+		for j := len(slots) - 1; j >= 0; j-- {
+			m.pool[slots[j]].RUnlock()
+		}
+	}
+}
+
+/*
+	Package method
+*/
+
+func (m *MutexPool) slots(keys []string) []uint32 {
+	return slots(keys, m.size)
+}
+
+func (m *RwMutexPool) slots(keys []string) []uint32 {
+	return slots(keys, m.size)
+}
+
+// slots returns the distinct slots of keys in ascending order.
+func slots(keys []string, size uint32) []uint32 {
+	list := make([]uint32, 0, len(keys))
+	for _, k := range keys {
+		list = append(list, sum(k)%size)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i] < list[j] })
+	n := 0
+	for i, v := range list {
+		if i == 0 || v != list[n-1] {
+			list[n] = v
+			n++
+		}
+	}
+	return list[:n]
+}
@@ -0,0 +1,60 @@
+package lock_test
+
+import (
+	"github.com/azeroth-sha/simple/lock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLockAllSameSlot(t *testing.T) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m := lock.NewMutexPool(1) // every key maps to the only slot
+		m.LockAll(`a`, `b`, `a`)()
+		rw := lock.NewRWMutexPool(1)
+		rw.LockAll(`a`, `b`)()
+		rw.RLockAll(`a`, `b`)()
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Fatal("LockAll deadlocked on keys sharing a slot")
+	}
+}
+
+func TestLockAllOverlap(t *testing.T) {
+	m := lock.NewMutexPool(4)
+	keys := []string{`a`, `b`, `c`, `d`, `e`}
+	var (
+		wg    sync.WaitGroup
+		count int
+	)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 200; j++ {
+				// overlapping key sets in different orders
+				a, b := keys[(i+j)%len(keys)], keys[(i+2*j)%len(keys)]
+				unlock := m.LockAll(b, a, keys[0])
+				count++
+				unlock()
+			}
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 10):
+		t.Fatal("LockAll deadlocked on overlapping keys")
+	}
+	if count != 16*200 {
+		t.Errorf("count %d, want %d", count, 16*200)
+	}
+}
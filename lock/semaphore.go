@@ -0,0 +1,296 @@
+package lock
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrWeight is returned when the requested weight is not positive or exceeds the size of the semaphore.
+var ErrWeight = errors.New(`lock: invalid semaphore weight`)
+
+// NewSemaphore returns a weighted semaphore with the given total weight, it panics if n is not positive.
+func NewSemaphore(n int64) *Semaphore {
+	if n <= 0 {
+		panic("lock: non-positive semaphore size")
+	}
+	return &Semaphore{size: n}
+}
+
+// Semaphore is a weighted semaphore. Waiters are served in FIFO order,
+// so a large request is not starved by smaller ones arriving later.
+// Each successful acquisition must be released once with the same weight.
+type Semaphore struct {
+	mu      sync.Mutex
+	size    int64
+	cur     int64
+	holders int
+	waiters list.List
+}
+
+// Acquire acquires weight n, blocking until it is available or ctx is done.
+func (s *Semaphore) Acquire(ctx context.Context, n int64) error {
+	if n <= 0 || n > s.size {
+		return ErrWeight
+	}
+	s.mu.Lock()
+	if s.size-s.cur >= n && s.waiters.Len() == 0 {
+		s.cur += n
+		s.holders++
+		s.mu.Unlock()
+		return nil
+	}
+	w := &semWaiter{n: n, ready: make(chan struct{})}
+	elem := s.waiters.PushBack(w)
+	s.mu.Unlock()
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		select {
+		case <-w.ready: // granted while cancelling, give it back
+			s.cur -= n
+			s.holders--
+		default:
+			front := s.waiters.Front() == elem
+			s.waiters.Remove(elem)
+			if !front {
+				return ctx.Err()
+			}
+		}
+		s.notify() // waiters behind may fit now
+		return ctx.Err()
+	}
+}
+
+// TryAcquire acquires weight n without blocking and reports whether it succeeded.
+func (s *Semaphore) TryAcquire(n int64) bool {
+	if n <= 0 {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.size-s.cur < n || s.waiters.Len() > 0 {
+		return false
+	}
+	s.cur += n
+	s.holders++
+	return true
+}
+
+// Release releases weight n acquired earlier.
+func (s *Semaphore) Release(n int64) {
+	if n <= 0 {
+		panic("lock: semaphore released with non-positive weight")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cur -= n; s.cur < 0 {
+		panic("lock: semaphore released more than held")
+	}
+	s.holders--
+	s.notify()
+}
+
+// Holders returns the number of acquisitions currently held.
+func (s *Semaphore) Holders() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.holders
+}
+
+// Waiters returns the number of goroutines waiting to acquire.
+func (s *Semaphore) Waiters() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.waiters.Len()
+}
+
+// NewKeyedLimiter returns a limiter allowing at most perKey concurrent holders per key,
+// and at most total holders over all keys; total <= 0 means no overall limit.
+// It panics if perKey is not positive.
+func NewKeyedLimiter(perKey, total int64) *KeyedLimiter {
+	if perKey <= 0 {
+		panic("lock: non-positive keyed limiter size")
+	}
+	cnt := runtime.NumCPU() * 4
+	k := &KeyedLimiter{
+		perKey: perKey,
+		shards: make([]*limiterShard, cnt),
+		size:   uint32(cnt),
+	}
+	if total > 0 {
+		k.total = NewSemaphore(total)
+	}
+	for i := 0; i < cnt; i++ {
+		k.shards[i] = &limiterShard{keys: make(map[string]*limiterKey)}
+	}
+	return k
+}
+
+// KeyedLimiter caps concurrency per key and overall.
+// The semaphore of a key is created on demand and freed once nobody holds or waits for it.
+type KeyedLimiter struct {
+	perKey int64
+	total  *Semaphore
+	shards []*limiterShard
+	size   uint32
+}
+
+// Acquire acquires a slot of key, blocking until it is available or ctx is done.
+// The slot of key is taken before the overall one, so callers of a busy key never tie up the overall limit;
+// a caller holding the slot of key while waiting for the overall one counts as a waiter of key.
+func (k *KeyedLimiter) Acquire(ctx context.Context, key string) error {
+	e := k.ref(key)
+	if err := e.sem.Acquire(ctx, 1); err != nil {
+		k.unref(key)
+		return err
+	}
+	if k.total != nil {
+		e.pending.Add(1)
+		err := k.total.Acquire(ctx, 1)
+		e.pending.Add(-1)
+		if err != nil {
+			e.sem.Release(1)
+			k.unref(key)
+			return err
+		}
+	}
+	return nil
+}
+
+// TryAcquire acquires a slot of key without blocking and reports whether it succeeded.
+func (k *KeyedLimiter) TryAcquire(key string) bool {
+	e := k.ref(key)
+	if !e.sem.TryAcquire(1) {
+		k.unref(key)
+		return false
+	}
+	if k.total != nil && !k.total.TryAcquire(1) {
+		e.sem.Release(1)
+		k.unref(key)
+		return false
+	}
+	return true
+}
+
+// Release releases a slot of key acquired earlier.
+func (k *KeyedLimiter) Release(key string) {
+	if k.total != nil {
+		k.total.Release(1)
+	}
+	s := k.shard(key)
+	s.mu.Lock()
+	e, ok := s.keys[key]
+	s.mu.Unlock()
+	if !ok {
+		panic("lock: release of unacquired key " + key)
+	}
+	e.sem.Release(1)
+	k.unref(key)
+}
+
+// Holders returns the number of slots of key currently held.
+func (k *KeyedLimiter) Holders(key string) int {
+	if e := k.lookup(key); e != nil {
+		return e.sem.Holders() - int(e.pending.Load())
+	}
+	return 0
+}
+
+// Waiters returns the number of goroutines waiting for a slot of key.
+func (k *KeyedLimiter) Waiters(key string) int {
+	if e := k.lookup(key); e != nil {
+		return e.sem.Waiters() + int(e.pending.Load())
+	}
+	return 0
+}
+
+// Total returns the semaphore enforcing the overall limit, or nil if there is none.
+func (k *KeyedLimiter) Total() *Semaphore {
+	return k.total
+}
+
+/*
+	Package method
+*/
+
+type semWaiter struct {
+	n     int64
+	ready chan struct{}
+}
+
+// notify grants waiters in order while their weight fits, must be called with mu held.
+func (s *Semaphore) notify() {
+	for {
+		front := s.waiters.Front()
+		if front == nil {
+			return
+		}
+		w := front.Value.(*semWaiter)
+		if s.size-s.cur < w.n {
+			return
+		}
+		s.cur += w.n
+		s.holders++
+		s.waiters.Remove(front)
+		close(w.ready)
+	}
+}
+
+type limiterShard struct {
+	mu   sync.Mutex
+	keys map[string]*limiterKey
+}
+
+type limiterKey struct {
+	sem     *Semaphore
+	ref     int
+	pending atomic.Int64 // holders of the key still waiting for the overall limit
+}
+
+func (k *KeyedLimiter) shard(key string) *limiterShard {
+	return k.shards[sum(key)%k.size]
+}
+
+// ref returns the entry of key, creating it if needed, and adds a reference.
+func (k *KeyedLimiter) ref(key string) *limiterKey {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	e, ok := s.keys[key]
+	if !ok {
+		e = &limiterKey{sem: NewSemaphore(k.perKey)}
+		s.keys[key] = e
+	}
+	e.ref++
+	return e
+}
+
+// unref drops a reference and frees the semaphore of key when it is no longer referenced.
+func (k *KeyedLimiter) unref(key string) {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.keys[key]; ok {
+		if e.ref--; e.ref <= 0 {
+			delete(s.keys, key)
+		}
+	}
+}
+
+// lookup returns the entry of key, or nil if nobody holds or waits for it.
+func (k *KeyedLimiter) lookup(key string) *limiterKey {
+	s := k.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if e, ok := s.keys[key]; ok {
+		return e
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+package lock_test
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple/lock"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSemaphoreFIFO(t *testing.T) {
+	s := lock.NewSemaphore(1)
+	ctx := context.Background()
+	if err := s.Acquire(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	var (
+		mu    sync.Mutex
+		order []int
+		wg    sync.WaitGroup
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if err := s.Acquire(ctx, 1); err != nil {
+				t.Error(err)
+				return
+			}
+			mu.Lock()
+			order = append(order, i)
+			mu.Unlock()
+			s.Release(1)
+		}(i)
+		waitFor(t, func() bool { return s.Waiters() == i+1 })
+	}
+	s.Release(1)
+	wg.Wait()
+	for i, v := range order {
+		if v != i {
+			t.Fatalf("acquired in order %v, want FIFO", order)
+		}
+	}
+}
+
+func TestSemaphoreNoBarging(t *testing.T) {
+	s := lock.NewSemaphore(2)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := s.Acquire(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	go func() { _ = s.Acquire(ctx, 2) }()
+	waitFor(t, func() bool { return s.Waiters() == 1 })
+	if s.TryAcquire(1) {
+		t.Error("small request overtook a waiting large one")
+	}
+}
+
+func TestSemaphoreCancelFront(t *testing.T) {
+	s := lock.NewSemaphore(2)
+	if err := s.Acquire(context.Background(), 1); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	front := make(chan error, 1)
+	go func() { front <- s.Acquire(ctx, 2) }()
+	waitFor(t, func() bool { return s.Waiters() == 1 })
+	behind := make(chan error, 1)
+	go func() { behind <- s.Acquire(context.Background(), 1) }()
+	waitFor(t, func() bool { return s.Waiters() == 2 })
+	cancel()
+	if err := <-front; !errors.Is(err, context.Canceled) {
+		t.Fatalf("front waiter error %v, want %v", err, context.Canceled)
+	}
+	select {
+	case err := <-behind:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("waiter behind a cancelled one not woken")
+	}
+	if n := s.Holders(); n != 2 {
+		t.Errorf("%d holders, want 2", n)
+	}
+}
+
+func TestSemaphoreWeight(t *testing.T) {
+	s := lock.NewSemaphore(2)
+	for _, n := range []int64{0, -1, 3} {
+		if err := s.Acquire(context.Background(), n); !errors.Is(err, lock.ErrWeight) {
+			t.Errorf("acquire %d error %v, want %v", n, err, lock.ErrWeight)
+		}
+		if s.TryAcquire(n) {
+			t.Errorf("try acquire %d succeeded", n)
+		}
+	}
+	if s.Holders() != 0 || !s.TryAcquire(2) {
+		t.Error("invalid weights changed the semaphore")
+	}
+	for name, f := range map[string]func(){
+		"NewSemaphore(0)":       func() { lock.NewSemaphore(0) },
+		"NewKeyedLimiter(0, 1)": func() { lock.NewKeyedLimiter(0, 1) },
+		"Release(-1)":           func() { s.Release(-1) },
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%s did not panic", name)
+				}
+			}()
+			f()
+		}()
+	}
+}
+
+func TestKeyedLimiterCounts(t *testing.T) {
+	k := lock.NewKeyedLimiter(2, 1)
+	ctx := context.Background()
+	if err := k.Acquire(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for _, key := range []string{"b", "a"} {
+		wg.Add(1)
+		go func(key string) {
+			defer wg.Done()
+			if err := k.Acquire(ctx, key); err != nil {
+				t.Error(err)
+			}
+		}(key)
+		waitFor(t, func() bool { return k.Waiters(key) == 1 })
+	}
+	// both wait for the overall limit, the one of "a" with a slot of "a" free
+	if k.Holders("a") != 1 || k.Holders("b") != 0 || k.Total().Waiters() != 2 {
+		t.Errorf("holders a=%d b=%d, total waiters %d, want 1, 0 and 2",
+			k.Holders("a"), k.Holders("b"), k.Total().Waiters())
+	}
+	k.Release("a")
+	waitFor(t, func() bool { return k.Holders("b") == 1 })
+	if k.Waiters("b") != 0 || k.Holders("a") != 0 || k.Waiters("a") != 1 {
+		t.Errorf("holders a=%d, waiters a=%d b=%d, want 0, 1 and 0", k.Holders("a"), k.Waiters("a"), k.Waiters("b"))
+	}
+	k.Release("b")
+	wg.Wait()
+	if k.Holders("a") != 1 || k.Waiters("a") != 0 {
+		t.Errorf("holders a=%d, waiters a=%d, want 1 and 0", k.Holders("a"), k.Waiters("a"))
+	}
+	k.Release("a")
+	if k.Holders("a") != 0 || k.Total().Holders() != 0 {
+		t.Error("slots left after release")
+	}
+}
@@ -0,0 +1,338 @@
+package lock
+
+import (
+	"context"
+	"github.com/azeroth-sha/simple"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SlotStat is the contention statistics of a pool slot.
+type SlotStat struct {
+	Slot      int           // index of the slot in the pool
+	Acquires  uint64        // successful acquisitions, read and write
+	Contended uint64        // acquisitions that had to wait
+	WaitTime  time.Duration // total time spent waiting
+	MaxWait   time.Duration // longest single wait
+	HoldTime  time.Duration // total time write locks were held
+	MaxHold   time.Duration // longest single write hold
+}
+
+// InstrumentOption configures an instrumented pool.
+type InstrumentOption func(*instrument)
+
+// WithWatchdog logs, through l, every write lock held longer than threshold together with the stack of its holder.
+// Holds are checked every threshold/2, but at most once per millisecond; Close stops the watchdog.
+// Capturing the stack on each acquisition is expensive, so this is meant for debugging.
+func WithWatchdog(threshold time.Duration, l simple.Logger) InstrumentOption {
+	return func(in *instrument) {
+		if threshold <= 0 || l == nil {
+			return
+		}
+		in.threshold = threshold
+		in.logger = l
+	}
+}
+
+// NewInstrumentedMutexPool returns a mutex pool of n slots that records wait and hold time per slot.
+func NewInstrumentedMutexPool(n int, opts ...InstrumentOption) *MutexPool {
+	p := NewMutexPool(n)
+	p.in = newInstrument(len(p.pool), opts)
+	for i, mu := range p.pool {
+		p.pool[i] = &instrumentedMutex{ContextMutex: mu, s: p.in.slots[i], in: p.in}
+	}
+	if p.in.start() {
+		runtime.SetFinalizer(p, func(p *MutexPool) { p.in.stop() })
+	}
+	return p
+}
+
+// NewInstrumentedRWMutexPool returns a rw mutex pool of n slots that records wait and hold time per slot.
+// Read locks are counted in acquisitions and wait time; hold time and the watchdog cover write locks only.
+func NewInstrumentedRWMutexPool(n int, opts ...InstrumentOption) *RwMutexPool {
+	p := NewRWMutexPool(n)
+	p.in = newInstrument(len(p.pool), opts)
+	for i, mu := range p.pool {
+		p.pool[i] = &instrumentedRWMutex{ContextRWMutex: mu, s: p.in.slots[i], in: p.in}
+	}
+	if p.in.start() {
+		runtime.SetFinalizer(p, func(p *RwMutexPool) { p.in.stop() })
+	}
+	return p
+}
+
+// Stats returns the statistics of the top slots with the longest total wait time, top <= 0 returns all slots.
+// It returns nil if the pool is not instrumented.
+func (m *MutexPool) Stats(top int) []SlotStat {
+	return m.in.stats(top)
+}
+
+// Close stops the watchdog of an instrumented pool. The pool stays usable and keeps its statistics.
+func (m *MutexPool) Close() {
+	m.in.stop()
+}
+
+// Stats returns the statistics of the top slots with the longest total wait time, top <= 0 returns all slots.
+// It returns nil if the pool is not instrumented.
+func (m *RwMutexPool) Stats(top int) []SlotStat {
+	return m.in.stats(top)
+}
+
+// Close stops the watchdog of an instrumented pool. The pool stays usable and keeps its statistics.
+func (m *RwMutexPool) Close() {
+	m.in.stop()
+}
+
+/*
+	Package method
+*/
+
+type instrument struct {
+	slots     []*slotStat
+	threshold time.Duration
+	logger    simple.Logger
+	closed    chan struct{}
+	closeOnce sync.Once
+}
+
+type slotStat struct {
+	acquires  atomic.Uint64
+	contended atomic.Uint64
+	wait      atomic.Int64
+	maxWait   atomic.Int64
+	hold      atomic.Int64
+	maxHold   atomic.Int64
+	holder    atomic.Pointer[holder]
+}
+
+type holder struct {
+	since    time.Time
+	stack    []byte
+	reported atomic.Bool
+}
+
+func newInstrument(n int, opts []InstrumentOption) *instrument {
+	in := &instrument{
+		slots:  make([]*slotStat, n),
+		closed: make(chan struct{}),
+	}
+	for i := range in.slots {
+		in.slots[i] = new(slotStat)
+	}
+	for _, opt := range opts {
+		opt(in)
+	}
+	return in
+}
+
+// start runs the watchdog if enabled and reports whether it was started.
+func (in *instrument) start() bool {
+	if in.threshold <= 0 {
+		return false
+	}
+	go func() {
+		tk := time.NewTicker(max(in.threshold/2, time.Millisecond))
+		defer tk.Stop()
+		for {
+			select {
+			case <-in.closed:
+				return
+			case now := <-tk.C:
+				in.check(now)
+			}
+		}
+	}()
+	return true
+}
+
+func (in *instrument) stop() {
+	if in == nil {
+		return
+	}
+	in.closeOnce.Do(func() { close(in.closed) })
+}
+
+// check logs the write locks held longer than the threshold, each hold is reported once.
+func (in *instrument) check(now time.Time) {
+	for i, s := range in.slots {
+		h := s.holder.Load()
+		if h == nil {
+			continue
+		}
+		if d := now.Sub(h.since); d > in.threshold && h.reported.CompareAndSwap(false, true) {
+			in.logger.Warnf("lock: slot %d held for %s, holder:\n%s", i, d, h.stack)
+		}
+	}
+}
+
+func (in *instrument) stats(top int) []SlotStat {
+	if in == nil {
+		return nil
+	}
+	list := make([]SlotStat, 0, len(in.slots))
+	for i, s := range in.slots {
+		list = append(list, SlotStat{
+			Slot:      i,
+			Acquires:  s.acquires.Load(),
+			Contended: s.contended.Load(),
+			WaitTime:  time.Duration(s.wait.Load()),
+			MaxWait:   time.Duration(s.maxWait.Load()),
+			HoldTime:  time.Duration(s.hold.Load()),
+			MaxHold:   time.Duration(s.maxHold.Load()),
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		if list[i].WaitTime != list[j].WaitTime {
+			return list[i].WaitTime > list[j].WaitTime
+		}
+		return list[i].Acquires > list[j].Acquires
+	})
+	if top > 0 && top < len(list) {
+		list = list[:top]
+	}
+	return list
+}
+
+// acquire takes the lock with try, falling back to wait when it is busy, and records the acquisition.
+func (in *instrument) acquire(s *slotStat, try func() bool, wait func() error, write bool) error {
+	begin := time.Now()
+	if !try() {
+		if err := wait(); err != nil {
+			return err
+		}
+		s.contended.Add(1)
+	}
+	now := time.Now()
+	s.acquires.Add(1)
+	d := int64(now.Sub(begin))
+	s.wait.Add(d)
+	storeMax(&s.maxWait, d)
+	if write {
+		h := &holder{since: now}
+		if in.threshold > 0 {
+			buf := make([]byte, 4096)
+			h.stack = buf[:runtime.Stack(buf, false)]
+		}
+		s.holder.Store(h)
+	}
+	return nil
+}
+
+// release records the hold time of the write lock.
+func (in *instrument) release(s *slotStat) {
+	h := s.holder.Swap(nil)
+	if h == nil {
+		return
+	}
+	d := int64(time.Since(h.since))
+	s.hold.Add(d)
+	storeMax(&s.maxHold, d)
+}
+
+func storeMax(v *atomic.Int64, n int64) {
+	for {
+		old := v.Load()
+		if n <= old || v.CompareAndSwap(old, n) {
+			return
+		}
+	}
+}
+
+// never is the wait of TryLock, which does not wait.
+func never() error {
+	return context.Canceled
+}
+
+type instrumentedMutex struct {
+	ContextMutex
+	s  *slotStat
+	in *instrument
+}
+
+func (m *instrumentedMutex) Lock() {
+	_ = m.in.acquire(m.s, m.ContextMutex.TryLock, func() error {
+		m.ContextMutex.Lock()
+		return nil
+	}, true)
+}
+
+func (m *instrumentedMutex) Unlock() {
+	m.in.release(m.s)
+	m.ContextMutex.Unlock()
+}
+
+func (m *instrumentedMutex) TryLock() bool {
+	return m.in.acquire(m.s, m.ContextMutex.TryLock, never, true) == nil
+}
+
+func (m *instrumentedMutex) LockContext(ctx context.Context) error {
+	return m.in.acquire(m.s, m.ContextMutex.TryLock, func() error {
+		return m.ContextMutex.LockContext(ctx)
+	}, true)
+}
+
+func (m *instrumentedMutex) TryLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.LockContext(ctx) == nil
+}
+
+type instrumentedRWMutex struct {
+	ContextRWMutex
+	s  *slotStat
+	in *instrument
+}
+
+func (m *instrumentedRWMutex) Lock() {
+	_ = m.in.acquire(m.s, m.ContextRWMutex.TryLock, func() error {
+		m.ContextRWMutex.Lock()
+		return nil
+	}, true)
+}
+
+func (m *instrumentedRWMutex) Unlock() {
+	m.in.release(m.s)
+	m.ContextRWMutex.Unlock()
+}
+
+func (m *instrumentedRWMutex) TryLock() bool {
+	return m.in.acquire(m.s, m.ContextRWMutex.TryLock, never, true) == nil
+}
+
+func (m *instrumentedRWMutex) LockContext(ctx context.Context) error {
+	return m.in.acquire(m.s, m.ContextRWMutex.TryLock, func() error {
+		return m.ContextRWMutex.LockContext(ctx)
+	}, true)
+}
+
+func (m *instrumentedRWMutex) TryLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.LockContext(ctx) == nil
+}
+
+func (m *instrumentedRWMutex) RLock() {
+	_ = m.in.acquire(m.s, m.ContextRWMutex.TryRLock, func() error {
+		m.ContextRWMutex.RLock()
+		return nil
+	}, false)
+}
+
+func (m *instrumentedRWMutex) TryRLock() bool {
+	return m.in.acquire(m.s, m.ContextRWMutex.TryRLock, never, false) == nil
+}
+
+func (m *instrumentedRWMutex) RLockContext(ctx context.Context) error {
+	return m.in.acquire(m.s, m.ContextRWMutex.TryRLock, func() error {
+		return m.ContextRWMutex.RLockContext(ctx)
+	}, false)
+}
+
+func (m *instrumentedRWMutex) TryRLockFor(d time.Duration) bool {
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	return m.RLockContext(ctx) == nil
+}
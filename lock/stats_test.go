@@ -0,0 +1,52 @@
+package lock_test
+
+import (
+	"github.com/azeroth-sha/simple/lock"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// warnLogger counts the warnings it receives.
+type warnLogger struct {
+	warns atomic.Int32
+}
+
+func (l *warnLogger) Debug(...interface{})          {}
+func (l *warnLogger) Info(...interface{})           {}
+func (l *warnLogger) Warn(...interface{})           { l.warns.Add(1) }
+func (l *warnLogger) Error(...interface{})          {}
+func (l *warnLogger) Fatal(...interface{})          {}
+func (l *warnLogger) Debugf(string, ...interface{}) {}
+func (l *warnLogger) Infof(string, ...interface{})  {}
+func (l *warnLogger) Warnf(string, ...interface{})  { l.warns.Add(1) }
+func (l *warnLogger) Errorf(string, ...interface{}) {}
+func (l *warnLogger) Fatalf(string, ...interface{}) {}
+
+func TestWatchdog(t *testing.T) {
+	l := new(warnLogger)
+	p := lock.NewInstrumentedMutexPool(4, lock.WithWatchdog(time.Nanosecond, l))
+	defer p.Close()
+	m := p.Get(`key`)
+	m.Lock()
+	waitFor(t, func() bool { return l.warns.Load() > 0 })
+	m.Unlock()
+	if st := p.Stats(1); len(st) != 1 || st[0].Acquires != 1 || st[0].HoldTime <= 0 {
+		t.Errorf("stats %+v", st)
+	}
+}
+
+func TestWatchdogClose(t *testing.T) {
+	l := new(warnLogger)
+	p := lock.NewInstrumentedRWMutexPool(4, lock.WithWatchdog(time.Millisecond, l))
+	p.Close()
+	p.Close()
+	m := p.Get(`key`)
+	m.Lock()
+	time.Sleep(time.Millisecond * 20)
+	m.Unlock()
+	if n := l.warns.Load(); n != 0 {
+		t.Errorf("%d warnings after Close", n)
+	}
+	lock.NewMutexPool(1).Close() // not instrumented
+}
@@ -0,0 +1,114 @@
+package studio
+
+import (
+	"sync"
+	"time"
+)
+
+// BatchHandler 表示一个批量事件处理器，一次处理一批同名事件。
+// 参数 es 是待处理的事件列表，按入队顺序排列。
+type BatchHandler func(es []Event)
+
+// AddBatchWorkstation 添加指定名称的批量工作站处理器
+func (eng *engine) AddBatchWorkstation(n string, size int, wait time.Duration, h BatchHandler) {
+	if !eng.isRunning() {
+		return
+	}
+	b := newBatcher(eng, size, wait, h)
+	eng.jobMu.Lock()
+	defer eng.jobMu.Unlock()
+	eng.jobMap[n] = append(eng.jobMap[n], b.add)
+	eng.batchers = append(eng.batchers, b)
+}
+
+// batcher 收集同名事件并按数量或时间批量提交给处理器
+type batcher struct {
+	eng   *engine       // 所属引擎
+	size  int           // 批次最大事件数量
+	wait  time.Duration // 批次最长等待时间
+	h     BatchHandler  // 批量处理器
+	mu    sync.Mutex    // 保护 buf 和 timer
+	buf   []Event       // 当前批次的事件
+	timer *time.Timer   // 当前批次的超时定时器
+}
+
+/*
+  内部方法
+*/
+
+// newBatcher 创建批量收集器（内部方法）
+func newBatcher(eng *engine, size int, wait time.Duration, h BatchHandler) *batcher {
+	if size <= 0 {
+		size = 1
+	}
+	return &batcher{
+		eng:  eng,
+		size: size,
+		wait: wait,
+		h:    h,
+		buf:  make([]Event, 0, size),
+	}
+}
+
+// add 将事件加入当前批次，批次已满时立即提交（内部方法）
+func (b *batcher) add(e Event) {
+	if c, ok := e.(*call); ok {
+		c.hold() // 批次执行完毕后才能生成请求结果
+	}
+	b.mu.Lock()
+	b.buf = append(b.buf, e)
+	if len(b.buf) == 1 && b.wait > 0 {
+		b.timer = time.AfterFunc(b.wait, b.flush)
+	}
+	var es []Event
+	if len(b.buf) >= b.size {
+		es = b.take()
+	}
+	b.mu.Unlock()
+	b.call(es)
+}
+
+// flush 提交当前批次中的全部事件（内部方法）
+func (b *batcher) flush() {
+	b.mu.Lock()
+	es := b.take()
+	b.mu.Unlock()
+	b.call(es)
+}
+
+// take 取出当前批次并停止定时器，调用方需持有锁（内部方法）
+func (b *batcher) take() []Event {
+	if b.timer != nil {
+		b.timer.Stop()
+		b.timer = nil
+	}
+	if len(b.buf) == 0 {
+		return nil
+	}
+	es := b.buf
+	b.buf = make([]Event, 0, b.size)
+	b.eng.batchWait.Add(1)
+	return es
+}
+
+// call 执行批量处理器（内部方法）
+func (b *batcher) call(es []Event) {
+	if len(es) == 0 {
+		return
+	}
+	defer b.eng.batchWait.Done()
+	defer release(es)
+	if b.eng.panicFunc != nil {
+		defer b.eng.panicFunc() // 恐慌恢复机制
+	}
+	b.h(es)
+}
+
+// release 结束批次中的请求，所有处理器执行完毕的请求生成结果（内部方法）
+func release(es []Event) {
+	for _, e := range es {
+		if c, ok := e.(*call); ok {
+			c.release()
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package studio_test
+
+import (
+	"context"
+	"github.com/azeroth-sha/simple/studio"
+	"testing"
+	"time"
+)
+
+func TestBatchCall(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	s.AddBatchWorkstation(`sum`, 2, time.Millisecond*20, func(es []studio.Event) {
+		for _, e := range es {
+			studio.Reply(e, e.Param().(int)*2, nil)
+		}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	// 批次未满，由等待超时提交
+	if v, err := s.Call(ctx, studio.NewEvent(`sum`, 1, nil)); err != nil || v != 2 {
+		t.Fatalf("call = %v, %v, want 2", v, err)
+	}
+	// 批次已满时立即提交
+	f1 := s.Async(studio.NewEvent(`sum`, 2, nil))
+	f2 := s.Async(studio.NewEvent(`sum`, 3, nil))
+	if v, err := f1.Wait(ctx); err != nil || v != 4 {
+		t.Errorf("async = %v, %v, want 4", v, err)
+	}
+	if v, err := f2.Wait(ctx); err != nil || v != 6 {
+		t.Errorf("async = %v, %v, want 6", v, err)
+	}
+}
+
+func TestBatchCallNoReply(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	s.AddBatchWorkstation(`drop`, 1, 0, func(es []studio.Event) {})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if _, err := s.Call(ctx, studio.NewEvent(`drop`, nil, nil)); err != studio.ErrNoReply {
+		t.Errorf("call error %v, want %v", err, studio.ErrNoReply)
+	}
+}
@@ -0,0 +1,432 @@
+package studio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/azeroth-sha/simple"
+	"github.com/azeroth-sha/simple/codec"
+	"github.com/azeroth-sha/simple/guid"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HeaderOrigin 是桥接事件携带的来源节点头信息，带有该头信息的事件不会被再次转发
+const HeaderOrigin = `x-studio-origin`
+
+// 桥接帧定义
+const (
+	frameEvent   byte = 1         // 事件帧：序号 + 信封
+	frameAck     byte = 2         // 确认帧：序号
+	frameHead         = 4 + 1 + 8 // 帧头长度：长度 + 类型 + 序号
+	maxFrameSize      = 16 << 20  // 单帧最大长度
+)
+
+// 桥接相关错误定义
+var (
+	ErrBridgeClosed = errors.New(`bridge closed`) // 桥接已关闭时返回的错误
+	ErrFrameInvalid = errors.New(`invalid frame`) // 收到无效帧时返回的错误
+)
+
+// Bridge 在多个进程的 Studio 之间转发事件。
+// 本地指定名称的事件被编码为信封，通过 TCP 或 Unix 套接字上的分帧协议发送到远端；
+// 远端收到事件后投递到其本地 Studio 并回复确认。
+// 未确认的事件在重连后重新发送，因此投递语义为至少一次，可通过 EventID 去重。
+type Bridge struct {
+	running   int32                 // 原子操作标记桥接运行状态（1运行中/0已关闭）
+	studio    Studio                // 本地工作室
+	node      string                // 本地节点标识
+	codecType codec.Type            // 信封编码类型
+	queueSize int                   // 每个远端的发送队列容量
+	window    int                   // 每个远端允许的未确认事件数量
+	retry     time.Duration         // 重连间隔
+	timeout   time.Duration         // 连接超时时间
+	logger    simple.Logger         // 日志记录器，可为空
+	closed    chan struct{}         // 桥接关闭信号通道
+	wait      *sync.WaitGroup       // 等待所有后台协程退出的同步器
+	mu        *sync.Mutex           // 保护以下字段
+	peers     []*peer               // 远端列表
+	listeners []net.Listener        // 监听器列表
+	conns     map[net.Conn]struct{} // 活动连接集合
+}
+
+// Forward 将本地指定名称的事件转发到所有远端。
+// 发送队列已满时转发处理器阻塞，从而对本地工作室形成背压。
+func (b *Bridge) Forward(names ...string) {
+	for _, n := range names {
+		b.studio.AddWorkstation(n, b.forward)
+	}
+}
+
+// Dial 添加一个远端，后台自动连接并在断开后重连。
+// 参数 network 为 "tcp" 或 "unix" 等，参数 addr 为远端地址。
+func (b *Bridge) Dial(network, addr string) {
+	if !b.isRunning() {
+		return
+	}
+	p := &peer{
+		bridge:   b,
+		network:  network,
+		addr:     addr,
+		queue:    make(chan []byte, b.queueSize),
+		window:   make(chan struct{}, b.window),
+		inflight: make(map[uint64][]byte),
+	}
+	b.mu.Lock()
+	b.peers = append(b.peers, p)
+	b.mu.Unlock()
+	b.wait.Add(1)
+	go p.run()
+}
+
+// Serve 在指定监听器上接收远端事件，直到监听器关闭或桥接关闭。
+func (b *Bridge) Serve(l net.Listener) error {
+	if !b.track(l) {
+		return ErrBridgeClosed
+	}
+	defer b.untrack(l)
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if !b.isRunning() {
+				return nil
+			}
+			return err
+		}
+		b.wait.Add(1)
+		go b.receive(conn)
+	}
+}
+
+// ListenAndServe 监听指定地址并接收远端事件。
+func (b *Bridge) ListenAndServe(network, addr string) error {
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return err
+	}
+	return b.Serve(l)
+}
+
+// Close 关闭桥接，断开所有连接并等待后台协程退出。
+// 发送队列中尚未发送或未确认的事件将被丢弃。
+func (b *Bridge) Close() error {
+	if atomic.SwapInt32(&b.running, 0) != 1 {
+		return nil
+	}
+	close(b.closed)
+	b.mu.Lock()
+	for _, l := range b.listeners {
+		_ = l.Close()
+	}
+	for conn := range b.conns {
+		_ = conn.Close()
+	}
+	b.mu.Unlock()
+	b.wait.Wait()
+	return nil
+}
+
+// NewBridge 创建一个绑定到本地工作室的桥接（采用选项模式配置）
+func NewBridge(s Studio, opts ...BridgeOption) *Bridge {
+	b := &Bridge{
+		running:   1,
+		studio:    s,
+		node:      guid.New().String(),
+		codecType: codec.MsgP,
+		queueSize: 1024,
+		window:    128,
+		retry:     time.Second,
+		timeout:   time.Second * 5,
+		logger:    nil,
+		closed:    make(chan struct{}),
+		wait:      new(sync.WaitGroup),
+		mu:        new(sync.Mutex),
+		peers:     make([]*peer, 0),
+		listeners: make([]net.Listener, 0),
+		conns:     make(map[net.Conn]struct{}),
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+/*
+  内部方法
+*/
+
+// forward 转发处理器，将事件编码后放入所有远端的发送队列（内部方法）
+func (b *Bridge) forward(e Event) {
+	if EventHeaders(e)[HeaderOrigin] != "" {
+		return // 来自其他节点的事件不再转发，避免环路
+	}
+	data, err := Encode(b.codecType, e, simple.MSString{HeaderOrigin: b.node})
+	if err != nil {
+		b.errorf("bridge encode %s error: %v", e.Name(), err)
+		return
+	}
+	b.mu.Lock()
+	peers := append([]*peer(nil), b.peers...)
+	b.mu.Unlock()
+	for _, p := range peers {
+		select {
+		case p.queue <- data:
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// receive 读取远端连接上的事件帧，投递到本地工作室后回复确认（内部方法）
+func (b *Bridge) receive(conn net.Conn) {
+	defer b.wait.Done()
+	if !b.track(conn) {
+		_ = conn.Close()
+		return
+	}
+	defer b.untrack(conn)
+	defer func() { _ = conn.Close() }()
+	r := bufio.NewReader(conn)
+	for {
+		kind, seq, data, err := readFrame(r)
+		if err != nil {
+			if err != io.EOF && b.isRunning() {
+				b.errorf("bridge receive %s error: %v", conn.RemoteAddr(), err)
+			}
+			return
+		}
+		if kind != frameEvent {
+			continue
+		}
+		if e, err := Decode(b.codecType, data); err != nil {
+			b.errorf("bridge decode error: %v", err) // 无法解码的事件直接确认，避免反复重发
+		} else if err = b.deliver(e); err != nil {
+			return
+		}
+		if err = writeFrame(conn, frameAck, seq, nil); err != nil {
+			return
+		}
+	}
+}
+
+// deliver 阻塞投递事件到本地工作室，工作室队列已满时停止读取以形成背压（内部方法）
+func (b *Bridge) deliver(e Event) error {
+	for {
+		err := b.studio.Task(e, false, b.retry)
+		if err != ErrFull {
+			return err
+		} else if !b.isRunning() {
+			return ErrBridgeClosed
+		}
+	}
+}
+
+// track 记录监听器或连接，桥接已关闭时返回 false（内部方法）
+func (b *Bridge) track(v io.Closer) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.isRunning() {
+		return false
+	}
+	switch c := v.(type) {
+	case net.Listener:
+		b.listeners = append(b.listeners, c)
+	case net.Conn:
+		b.conns[c] = struct{}{}
+	}
+	return true
+}
+
+// untrack 移除记录的监听器或连接（内部方法）
+func (b *Bridge) untrack(v io.Closer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch c := v.(type) {
+	case net.Listener:
+		for i, l := range b.listeners {
+			if l == c {
+				b.listeners = append(b.listeners[:i], b.listeners[i+1:]...)
+				break
+			}
+		}
+	case net.Conn:
+		delete(b.conns, c)
+	}
+}
+
+// errorf 记录错误日志（内部方法）
+func (b *Bridge) errorf(format string, v ...interface{}) {
+	if b.logger != nil {
+		b.logger.Errorf(format, v...)
+	}
+}
+
+// isRunning 检查桥接是否运行中（内部方法）
+func (b *Bridge) isRunning() bool {
+	return atomic.LoadInt32(&b.running) == 1
+}
+
+// peer 表示一个远端，维护发送队列与未确认事件
+type peer struct {
+	bridge   *Bridge
+	network  string            // 远端网络类型
+	addr     string            // 远端地址
+	queue    chan []byte       // 待发送的信封
+	window   chan struct{}     // 未确认事件令牌
+	mu       sync.Mutex        // 保护 seq 和 inflight
+	seq      uint64            // 最近分配的序号
+	inflight map[uint64][]byte // 已发送未确认的信封
+}
+
+// run 连接远端并在断开后按间隔重连（内部方法）
+func (p *peer) run() {
+	b := p.bridge
+	defer b.wait.Done()
+	for b.isRunning() {
+		conn, err := net.DialTimeout(p.network, p.addr, b.timeout)
+		if err != nil {
+			b.errorf("bridge dial %s error: %v", p.addr, err)
+		} else if b.track(conn) {
+			p.serve(conn)
+			b.untrack(conn)
+			_ = conn.Close()
+		} else {
+			_ = conn.Close()
+		}
+		select {
+		case <-b.closed:
+			return
+		case <-time.After(b.retry):
+		}
+	}
+}
+
+// serve 在一个连接上重发未确认事件并持续发送队列中的事件（内部方法）
+func (p *peer) serve(conn net.Conn) {
+	b := p.bridge
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		p.ack(conn)
+	}()
+	defer func() {
+		_ = conn.Close()
+		<-done
+	}()
+	for _, seq := range p.pending() {
+		if err := writeFrame(conn, frameEvent, seq, p.get(seq)); err != nil {
+			return
+		}
+	}
+	for {
+		select {
+		case p.window <- struct{}{}: // 获取发送令牌，未确认事件过多时在此等待
+		case <-done:
+			return
+		case <-b.closed:
+			return
+		}
+		select {
+		case data := <-p.queue:
+			seq := p.push(data)
+			if err := writeFrame(conn, frameEvent, seq, data); err != nil {
+				b.errorf("bridge send %s error: %v", p.addr, err)
+				return
+			}
+		case <-done:
+			<-p.window
+			return
+		case <-b.closed:
+			<-p.window
+			return
+		}
+	}
+}
+
+// ack 读取确认帧并释放对应的未确认事件（内部方法）
+func (p *peer) ack(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	for {
+		kind, seq, _, err := readFrame(r)
+		if err != nil {
+			return
+		}
+		if kind == frameAck && p.pop(seq) {
+			<-p.window
+		}
+	}
+}
+
+// push 为信封分配序号并记录为未确认（内部方法）
+func (p *peer) push(data []byte) uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.seq++
+	p.inflight[p.seq] = data
+	return p.seq
+}
+
+// pop 移除已确认的信封（内部方法）
+func (p *peer) pop(seq uint64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if _, ok := p.inflight[seq]; !ok {
+		return false
+	}
+	delete(p.inflight, seq)
+	return true
+}
+
+// get 获取未确认的信封（内部方法）
+func (p *peer) get(seq uint64) []byte {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.inflight[seq]
+}
+
+// pending 按序号升序返回所有未确认事件的序号（内部方法）
+func (p *peer) pending() []uint64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	all := make([]uint64, 0, len(p.inflight))
+	for seq := range p.inflight {
+		all = append(all, seq)
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i] < all[j] })
+	return all
+}
+
+// writeFrame 写入一帧：4字节长度 + 1字节类型 + 8字节序号 + 数据（内部方法）
+func writeFrame(w io.Writer, kind byte, seq uint64, data []byte) error {
+	buf := make([]byte, frameHead+len(data))
+	binary.BigEndian.PutUint32(buf[:4], uint32(1+8+len(data)))
+	buf[4] = kind
+	binary.BigEndian.PutUint64(buf[5:frameHead], seq)
+	copy(buf[frameHead:], data)
+	_, err := w.Write(buf)
+	return err
+}
+
+// readFrame 读取一帧（内部方法）
+func readFrame(r io.Reader) (kind byte, seq uint64, data []byte, err error) {
+	head := make([]byte, frameHead)
+	if _, err = io.ReadFull(r, head); err != nil {
+		return 0, 0, nil, err
+	}
+	size := binary.BigEndian.Uint32(head[:4])
+	if size < 1+8 || size > maxFrameSize {
+		return 0, 0, nil, fmt.Errorf("%w: size %d", ErrFrameInvalid, size)
+	}
+	kind = head[4]
+	seq = binary.BigEndian.Uint64(head[5:])
+	data = make([]byte, size-1-8)
+	if _, err = io.ReadFull(r, data); err != nil {
+		return 0, 0, nil, err
+	}
+	return kind, seq, data, nil
+}
@@ -0,0 +1,137 @@
+package studio_test
+
+import (
+	"github.com/azeroth-sha/simple/studio"
+	"net"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector 记录收到的事件，按事件标识去重
+type collector struct {
+	mu  sync.Mutex
+	ids map[string]int
+}
+
+func (c *collector) handle(e studio.Event) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ids[studio.EventID(e).String()]++
+}
+
+func (c *collector) unique() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.ids)
+}
+
+func (c *collector) total() (n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cnt := range c.ids {
+		n += cnt
+	}
+	return n
+}
+
+func (c *collector) waitFor(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second * 5)
+	for c.unique() < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d events, want %d", c.unique(), n)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// loopback 创建一对通过桥接相连的工作室
+func loopback(t *testing.T, network, addr string) (src, dst studio.Studio, col *collector, dstBridge *studio.Bridge, srcBridge *studio.Bridge) {
+	t.Helper()
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	col = &collector{ids: make(map[string]int)}
+	src, dst = studio.New(), studio.New()
+	dst.AddWorkstation(`ping`, col.handle)
+	dstBridge = studio.NewBridge(dst)
+	go func() { _ = dstBridge.Serve(l) }()
+	srcBridge = studio.NewBridge(src, studio.WithBridgeRetry(time.Millisecond*50), studio.WithBridgeWindow(8))
+	srcBridge.Forward(`ping`)
+	srcBridge.Dial(network, l.Addr().String())
+	t.Cleanup(func() {
+		_ = srcBridge.Close()
+		_ = dstBridge.Close()
+		src.Release()
+		dst.Release()
+	})
+	return src, dst, col, dstBridge, srcBridge
+}
+
+func publish(t *testing.T, s studio.Studio, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if err := s.Task(studio.NewEvent(`ping`, i, nil), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestBridgeTCP(t *testing.T) {
+	src, _, col, _, _ := loopback(t, `tcp`, `127.0.0.1:0`)
+	publish(t, src, 100)
+	col.waitFor(t, 100)
+}
+
+func TestBridgeUnix(t *testing.T) {
+	src, _, col, _, _ := loopback(t, `unix`, filepath.Join(t.TempDir(), `bridge.sock`))
+	publish(t, src, 100)
+	col.waitFor(t, 100)
+}
+
+func TestBridgeReconnect(t *testing.T) {
+	addr := filepath.Join(t.TempDir(), `bridge.sock`)
+	src, dst, col, dstBridge, _ := loopback(t, `unix`, addr)
+	publish(t, src, 10)
+	col.waitFor(t, 10)
+
+	_ = dstBridge.Close() // 远端下线期间发布的事件保留在发送队列中
+	publish(t, src, 10)
+	time.Sleep(time.Millisecond * 100)
+
+	l, err := net.Listen(`unix`, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restarted := studio.NewBridge(dst)
+	defer func() { _ = restarted.Close() }()
+	go func() { _ = restarted.Serve(l) }()
+	col.waitFor(t, 20)
+}
+
+func TestBridgeNoLoop(t *testing.T) {
+	src, dst, col, _, _ := loopback(t, `tcp`, `127.0.0.1:0`)
+	l, err := net.Listen(`tcp`, `127.0.0.1:0`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := &collector{ids: make(map[string]int)}
+	src.AddWorkstation(`ping`, back.handle)
+	srcServer := studio.NewBridge(src)
+	defer func() { _ = srcServer.Close() }()
+	go func() { _ = srcServer.Serve(l) }()
+	dstBridge := studio.NewBridge(dst)
+	defer func() { _ = dstBridge.Close() }()
+	dstBridge.Forward(`ping`)
+	dstBridge.Dial(`tcp`, l.Addr().String())
+
+	publish(t, src, 10)
+	col.waitFor(t, 10)
+	time.Sleep(time.Millisecond * 100)
+	if n := back.total(); n != 10 {
+		t.Fatalf("source handled %d events, want only its own 10", n)
+	}
+}
@@ -0,0 +1,218 @@
+package studio
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// 请求/应答相关错误定义
+var (
+	ErrNoReply    = errors.New(`event has no reply`)         // 所有处理器执行完毕但没有任何回复
+	ErrMultiReply = errors.New(`event has multiple replies`) // ReplyOnly 策略下出现多个回复
+)
+
+// ReplyPolicy 表示多个处理器同时回复时的应答策略
+type ReplyPolicy uint8
+
+const (
+	ReplyFirst ReplyPolicy = iota // 采用第一个回复，其余回复被丢弃
+	ReplyAll                      // 等待所有处理器执行完毕，收集全部回复（结果类型为 []any）
+	ReplyOnly                     // 仅允许一个处理器回复，出现多个回复时返回 ErrMultiReply
+)
+
+// Future 表示一个尚未完成的请求结果
+type Future interface {
+	// Done 返回请求完成信号通道，请求完成后该通道被关闭。
+	Done() <-chan struct{}
+
+	// Result 阻塞等待请求完成并返回结果。
+	Result() (any, error)
+
+	// Wait 等待请求完成并返回结果，ctx 取消时返回 ctx.Err()。
+	Wait(ctx context.Context) (any, error)
+}
+
+// Reply 回复一个通过 Call 或 Async 发布的事件。
+// 参数 e 是处理器收到的事件对象。
+// 参数 v 是回复的结果，参数 err 是回复的错误。
+// 返回值为 bool 类型，表示回复是否被接受；普通事件或已完成的请求返回 false。
+func Reply(e Event, v any, err error) bool {
+	c, ok := e.(*call)
+	if !ok {
+		return false
+	}
+	return c.reply(v, err)
+}
+
+// Unwrap 返回通过 Call 或 Async 发布的原始事件，其他事件原样返回。
+// 处理器收到的请求事件携带应答状态，对事件做类型断言前应先调用 Unwrap；回复时仍需传入处理器收到的事件。
+func Unwrap(e Event) Event {
+	if c, ok := e.(*call); ok {
+		return c.Event
+	}
+	return e
+}
+
+// call 是带应答的事件，实现 Event 与 Future 接口
+type call struct {
+	Event
+	policy  ReplyPolicy   // 应答策略
+	mu      sync.Mutex    // 保护以下字段
+	refs    int           // 尚未执行完毕的分发与批次数量
+	done    chan struct{} // 请求完成信号通道
+	replies []any         // 已收到的回复
+	errs    []error       // 已收到的错误
+	val     any           // 最终结果
+	err     error         // 最终错误
+}
+
+// Done 返回请求完成信号通道
+func (c *call) Done() <-chan struct{} {
+	return c.done
+}
+
+// Result 阻塞等待请求完成并返回结果
+func (c *call) Result() (any, error) {
+	return c.Wait(context.Background())
+}
+
+// Wait 等待请求完成并返回结果
+func (c *call) Wait(ctx context.Context) (any, error) {
+	select {
+	case <-c.done:
+		return c.val, c.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// Call 发布一个事件并等待处理器的回复
+func (eng *engine) Call(ctx context.Context, e Event, p ...ReplyPolicy) (any, error) {
+	c := eng.newCall(e, p...)
+	if err := eng.enqueue(ctx, c); err != nil {
+		return nil, err
+	}
+	return c.Wait(ctx)
+}
+
+// Async 非阻塞地发布一个事件，返回其结果的 Future
+// 事件队列已满或引擎已释放时，返回的 Future 立即以对应错误完成
+func (eng *engine) Async(e Event, p ...ReplyPolicy) Future {
+	c := eng.newCall(e, p...)
+	if err := eng.Task(c, false); err != nil {
+		c.resolve(nil, err)
+	}
+	return c
+}
+
+/*
+  内部方法
+*/
+
+// enqueue 将请求放入事件管道，ctx 结束时放弃等待（内部方法）
+func (eng *engine) enqueue(ctx context.Context, c *call) error {
+	if !eng.isRunning() {
+		return ErrReleased
+	}
+	eng.sendMu.RLock()
+	defer eng.sendMu.RUnlock()
+	if !eng.isRunning() {
+		return ErrReleased
+	}
+	select {
+	case eng.pipeline <- c:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-eng.closed:
+		return ErrReleased
+	}
+}
+
+// newCall 创建一个带应答的事件（内部方法）
+func (eng *engine) newCall(e Event, p ...ReplyPolicy) *call {
+	c := &call{
+		Event:  e,
+		policy: eng.policy,
+		done:   make(chan struct{}),
+	}
+	if len(p) > 0 {
+		c.policy = p[0]
+	}
+	return c
+}
+
+// reply 记录一个回复（内部方法）
+func (c *call) reply(v any, err error) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.isDone() {
+		return false
+	}
+	c.replies = append(c.replies, v)
+	c.errs = append(c.errs, err)
+	if c.policy == ReplyFirst {
+		c.complete(v, err)
+	}
+	return true
+}
+
+// hold 登记一个尚未执行完毕的分发或批次（内部方法）
+func (c *call) hold() {
+	c.mu.Lock()
+	c.refs++
+	c.mu.Unlock()
+}
+
+// release 结束一个分发或批次，全部结束后生成请求结果（内部方法）
+func (c *call) release() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.refs--; c.refs > 0 {
+		return
+	}
+	c.finish()
+}
+
+// finish 在所有处理器执行完毕后根据策略生成最终结果，调用方需持有锁（内部方法）
+func (c *call) finish() {
+	if c.isDone() {
+		return
+	}
+	switch {
+	case len(c.replies) == 0:
+		c.complete(nil, ErrNoReply)
+	case c.policy == ReplyAll:
+		c.complete(c.replies, errors.Join(c.errs...))
+	case c.policy == ReplyOnly && len(c.replies) > 1:
+		c.complete(nil, ErrMultiReply)
+	default:
+		c.complete(c.replies[0], c.errs[0])
+	}
+}
+
+// resolve 直接以指定结果完成请求（内部方法）
+func (c *call) resolve(v any, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isDone() {
+		c.complete(v, err)
+	}
+}
+
+// complete 设置最终结果并关闭完成通道，调用方需持有锁（内部方法）
+func (c *call) complete(v any, err error) {
+	c.val, c.err = v, err
+	close(c.done)
+}
+
+// isDone 检查请求是否已完成（内部方法）
+func (c *call) isDone() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,102 @@
+package studio_test
+
+import (
+	"context"
+	"errors"
+	"github.com/azeroth-sha/simple"
+	"github.com/azeroth-sha/simple/studio"
+	"sort"
+	"testing"
+	"time"
+)
+
+// order 是自定义事件类型
+type order struct {
+	id int
+}
+
+func (o *order) Occurred() time.Time  { return time.Time{} }
+func (o *order) Name() string         { return `order` }
+func (o *order) Param() any           { return o.id }
+func (o *order) Additive() simple.Map { return nil }
+
+func TestCallUnwrap(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	s.AddWorkstation(`order`, func(e studio.Event) {
+		o, ok := studio.Unwrap(e).(*order)
+		if !ok {
+			studio.Reply(e, nil, errors.New(`not an order`))
+			return
+		}
+		studio.Reply(e, o.id, nil)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if v, err := s.Call(ctx, &order{id: 7}); err != nil || v != 7 {
+		t.Errorf("call = %v, %v, want 7", v, err)
+	}
+	if e := studio.NewEvent(`plain`, nil, nil); studio.Unwrap(e) != e || studio.Reply(e, 1, nil) {
+		t.Error("plain event treated as a call")
+	}
+}
+
+func TestCallPolicy(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	for i := 1; i <= 3; i++ {
+		v := i
+		s.AddWorkstation(`multi`, func(e studio.Event) { studio.Reply(e, v, nil) })
+	}
+	s.AddWorkstation(`single`, func(e studio.Event) { studio.Reply(e, `only`, nil) })
+	s.AddWorkstation(`single`, func(studio.Event) {})
+	s.AddWorkstation(`silent`, func(studio.Event) {})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+
+	if v, err := s.Call(ctx, studio.NewEvent(`multi`, nil, nil), studio.ReplyFirst); err != nil || v == nil {
+		t.Errorf("first = %v, %v", v, err)
+	}
+	v, err := s.Call(ctx, studio.NewEvent(`multi`, nil, nil), studio.ReplyAll)
+	list, ok := v.([]any)
+	if err != nil || !ok || len(list) != 3 {
+		t.Fatalf("all = %v, %v", v, err)
+	}
+	got := make([]int, 0, len(list))
+	for _, x := range list {
+		got = append(got, x.(int))
+	}
+	sort.Ints(got)
+	if got[0] != 1 || got[1] != 2 || got[2] != 3 {
+		t.Errorf("all = %v, want [1 2 3]", got)
+	}
+	if _, err = s.Call(ctx, studio.NewEvent(`multi`, nil, nil), studio.ReplyOnly); !errors.Is(err, studio.ErrMultiReply) {
+		t.Errorf("only error %v, want %v", err, studio.ErrMultiReply)
+	}
+	if v, err = s.Call(ctx, studio.NewEvent(`single`, nil, nil), studio.ReplyOnly); err != nil || v != `only` {
+		t.Errorf("only = %v, %v", v, err)
+	}
+	if _, err = s.Call(ctx, studio.NewEvent(`silent`, nil, nil)); !errors.Is(err, studio.ErrNoReply) {
+		t.Errorf("silent error %v, want %v", err, studio.ErrNoReply)
+	}
+}
+
+func TestAsync(t *testing.T) {
+	s := studio.New(studio.WithReplyPolicy(studio.ReplyAll))
+	s.AddWorkstation(`echo`, func(e studio.Event) { studio.Reply(e, e.Param(), nil) })
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	f := s.Async(studio.NewEvent(`echo`, `hi`, nil))
+	select {
+	case <-f.Done():
+	case <-ctx.Done():
+		t.Fatal("async not done")
+	}
+	if v, err := f.Result(); err != nil || len(v.([]any)) != 1 || v.([]any)[0] != `hi` {
+		t.Errorf("async = %v, %v", v, err)
+	}
+	_ = s.Release()
+	if _, err := s.Async(studio.NewEvent(`echo`, nil, nil)).Wait(ctx); !errors.Is(err, studio.ErrReleased) {
+		t.Errorf("async after release error %v, want %v", err, studio.ErrReleased)
+	}
+}
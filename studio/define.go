@@ -1,6 +1,7 @@
 package studio
 
 import (
+	"context"
 	"github.com/azeroth-sha/simple"
 	"time"
 )
@@ -31,8 +32,20 @@ type Event interface {
 // Studio 表示一个工作室，用于管理和处理事件。
 type Studio interface {
 	// Release 释放工作室资源，停止所有工作线程。
-	// 调用后，工作室将不再接受新任务，并等待所有任务处理完成。
-	Release()
+	// 调用后，工作室将不再接受新任务；配置了 WithDrainTimeout 时在期限内处理完队列中的剩余事件。
+	// 返回值为 error 类型，存在未处理的事件时为 *ReleaseError，其中列出了这些事件。
+	Release() error
+
+	// Drain 停止接收新任务，处理队列中的剩余事件直到 ctx 结束，然后释放工作室。
+	// 返回值为 error 类型，存在未处理的事件时为 *ReleaseError，其中列出了这些事件。
+	Drain(ctx context.Context) error
+
+	// Start 阻塞直到工作室被释放，与 Stop 一起使工作室实现 grace.Server 接口。
+	// 返回值为 error 类型，工作室已释放时返回 ErrReleased。
+	Start() error
+
+	// Stop 释放工作室，等同于 Release。
+	Stop() error
 
 	// Task 发布一个任务到工作室。
 	// 参数 e 是待处理的事件对象。
@@ -41,6 +54,20 @@ type Studio interface {
 	// 返回值为 error 类型，表示任务发布的结果（成功或失败）。
 	Task(e Event, block bool, exp ...time.Duration) error
 
+	// Call 发布一个事件并等待处理器通过 Reply 给出的回复。
+	// 参数 ctx 用于控制入队与等待回复的超时或取消。
+	// 参数 e 是待处理的事件对象。
+	// 参数 p 是可选参数，指定本次请求的应答策略；未提供时使用引擎的默认策略。
+	// 返回值为处理器回复的结果与错误；ReplyAll 策略下结果类型为 []any。
+	// 处理器收到的是携带应答状态的事件，可通过 Unwrap 取得 e 本身。
+	Call(ctx context.Context, e Event, p ...ReplyPolicy) (any, error)
+
+	// Async 非阻塞地发布一个事件，返回其结果的 Future。
+	// 参数 e 是待处理的事件对象。
+	// 参数 p 是可选参数，指定本次请求的应答策略；未提供时使用引擎的默认策略。
+	// 事件队列已满或工作室已释放时，返回的 Future 立即以对应错误完成。
+	Async(e Event, p ...ReplyPolicy) Future
+
 	// SetWorkstation 设置指定名称的工作站处理器。
 	// 参数 n 是工作站的名称。
 	// 参数 h 是事件处理器。
@@ -53,6 +80,21 @@ type Studio interface {
 	// 如果工作站已存在，则追加处理器到现有列表中。
 	AddWorkstation(n string, h Handler)
 
+	// AddBatchWorkstation 添加指定名称的批量工作站处理器。
+	// 参数 n 是工作站的名称。
+	// 参数 size 是批次的最大事件数量，小于等于 0 时按 1 处理。
+	// 参数 wait 是批次的最长等待时间，小于等于 0 时仅在批次已满时提交。
+	// 参数 h 是批量事件处理器。
+	// 同名事件被收集为批次，达到 size 或等待超过 wait 后一次性交给处理器；释放时未满的批次也会被提交。
+	// 通过 Call 或 Async 发布的事件在所在批次执行完毕后才生成结果，批量处理器可对其调用 Reply。
+	AddBatchWorkstation(n string, size int, wait time.Duration, h BatchHandler)
+
+	// SetThrottle 设置指定名称工作站的节流策略。
+	// 参数 n 是工作站的名称。
+	// 参数 t 是由 RateLimit、Debounce 或 Coalesce 创建的节流策略，nil 表示移除。
+	// 被丢弃或被取代的请求事件以 ErrThrottled 结束；释放时被延迟的事件会被立即处理。
+	SetThrottle(n string, t Throttle)
+
 	// Recycle 设置全局回收处理器，用于处理未匹配的事件。
 	// 参数 h 是事件处理器。
 	Recycle(h Handler)
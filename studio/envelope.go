@@ -0,0 +1,185 @@
+package studio
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple"
+	"github.com/azeroth-sha/simple/codec"
+	"github.com/azeroth-sha/simple/guid"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// 信封相关错误定义
+var (
+	ErrTypeRegistered = errors.New(`param type already registered`) // 参数类型名称或类型重复注册
+)
+
+// 参数类型注册表
+var (
+	typeMu    = new(sync.RWMutex)
+	typeByKey = make(map[string]reflect.Type)
+	keyByType = make(map[reflect.Type]string)
+)
+
+// Envelope 是事件的标准传输格式，可通过 codec 编码后跨进程传递或持久化。
+type Envelope struct {
+	ID       guid.GUID       `json:"id" msgpack:"id"`                                 // 事件的唯一标识
+	Name     string          `json:"name" msgpack:"name"`                             // 事件的名称
+	Occurred time.Time       `json:"occurred" msgpack:"occurred"`                     // 事件发生的时间
+	Type     string          `json:"type,omitempty" msgpack:"type,omitempty"`         // 事件参数的注册类型名称
+	Param    any             `json:"param,omitempty" msgpack:"param,omitempty"`       // 事件的主要参数
+	Additive simple.Map      `json:"additive,omitempty" msgpack:"additive,omitempty"` // 事件的附加参数
+	Headers  simple.MSString `json:"headers,omitempty" msgpack:"headers,omitempty"`   // 事件的头信息
+}
+
+// Event 将信封还原为事件。
+// 返回值为 Event 接口类型，可通过 EventID 与 EventHeaders 获取标识与头信息。
+func (env *Envelope) Event() Event {
+	return &message{
+		t: env.Occurred,
+		n: env.Name,
+		d: env.Param,
+		a: env.Additive,
+		i: env.ID,
+		h: env.Headers,
+	}
+}
+
+// Marshal 使用指定的编码类型编码信封。
+func (env *Envelope) Marshal(t codec.Type) ([]byte, error) {
+	return codec.Marshal(t, env)
+}
+
+// Unmarshal 使用指定的编码类型解码信封，并根据注册表还原参数类型。
+// 参数类型未注册时，Param 保留解码器生成的通用值。
+func (env *Envelope) Unmarshal(t codec.Type, b []byte) error {
+	if err := codec.Unmarshal(t, b, env); err != nil {
+		return err
+	}
+	rt, ok := lookupType(env.Type)
+	if !ok || env.Param == nil {
+		return nil
+	}
+	raw, err := codec.Marshal(t, env.Param)
+	if err != nil {
+		return err
+	}
+	ptr := reflect.New(rt)
+	if err = codec.Unmarshal(t, raw, ptr.Interface()); err != nil {
+		return err
+	}
+	env.Param = ptr.Elem().Interface()
+	return nil
+}
+
+// NewEnvelope 将事件封装为信封。
+// 参数 e 是待封装的事件对象。
+// 参数 h 是可选参数，表示追加的头信息，与事件已有的头信息合并。
+// 事件没有唯一标识时生成新的 guid.GUID。
+func NewEnvelope(e Event, h ...simple.MSString) *Envelope {
+	e = Unwrap(e)
+	env := &Envelope{
+		ID:       EventID(e),
+		Name:     e.Name(),
+		Occurred: e.Occurred(),
+		Param:    e.Param(),
+		Additive: e.Additive(),
+		Headers:  nil,
+	}
+	if env.ID.Empty() {
+		env.ID = guid.New()
+	}
+	if env.Param != nil {
+		env.Type, _ = lookupKey(reflect.TypeOf(env.Param))
+	}
+	for _, src := range append([]simple.MSString{EventHeaders(e)}, h...) {
+		for k, v := range src {
+			if env.Headers == nil {
+				env.Headers = make(simple.MSString)
+			}
+			env.Headers[k] = v
+		}
+	}
+	return env
+}
+
+// Encode 将事件封装为信封并使用指定的编码类型编码。
+func Encode(t codec.Type, e Event, h ...simple.MSString) ([]byte, error) {
+	return NewEnvelope(e, h...).Marshal(t)
+}
+
+// Decode 使用指定的编码类型解码信封并还原为事件。
+func Decode(t codec.Type, b []byte) (Event, error) {
+	env := new(Envelope)
+	if err := env.Unmarshal(t, b); err != nil {
+		return nil, err
+	}
+	return env.Event(), nil
+}
+
+// Register 注册事件参数类型，用于解码时还原 Param 的具体类型。
+// 参数 name 是类型在信封中的名称，需在所有进程间保持一致。
+// 参数 v 是该类型的一个示例值，例如 Order{} 或 (*Order)(nil)。
+func Register(name string, v any) error {
+	rt := reflect.TypeOf(v)
+	typeMu.Lock()
+	defer typeMu.Unlock()
+	if _, ok := typeByKey[name]; ok {
+		return ErrTypeRegistered
+	}
+	if _, ok := keyByType[rt]; ok {
+		return ErrTypeRegistered
+	}
+	typeByKey[name] = rt
+	keyByType[rt] = name
+	return nil
+}
+
+// MustRegister 注册事件参数类型，失败时触发恐慌。
+func MustRegister(name string, v any) {
+	if err := Register(name, v); err != nil {
+		panic(err)
+	}
+}
+
+// EventID 返回事件的唯一标识。
+// 事件不携带标识时返回 guid.NULL。
+func EventID(e Event) guid.GUID {
+	if m, ok := Unwrap(e).(interface{ ID() guid.GUID }); ok {
+		return m.ID()
+	}
+	return guid.NULL
+}
+
+// EventHeaders 返回事件的头信息。
+// 事件不携带头信息时返回 nil。
+func EventHeaders(e Event) simple.MSString {
+	if m, ok := Unwrap(e).(interface{ Headers() simple.MSString }); ok {
+		return m.Headers()
+	}
+	return nil
+}
+
+/*
+  内部方法
+*/
+
+// lookupType 根据名称查询注册的参数类型（内部方法）
+func lookupType(name string) (reflect.Type, bool) {
+	if name == "" {
+		return nil, false
+	}
+	typeMu.RLock()
+	defer typeMu.RUnlock()
+	rt, ok := typeByKey[name]
+	return rt, ok
+}
+
+// lookupKey 根据参数类型查询注册的名称（内部方法）
+func lookupKey(rt reflect.Type) (string, bool) {
+	typeMu.RLock()
+	defer typeMu.RUnlock()
+	name, ok := keyByType[rt]
+	return name, ok
+}
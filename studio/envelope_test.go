@@ -0,0 +1,60 @@
+package studio_test
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple/codec"
+	"github.com/azeroth-sha/simple/studio"
+	"testing"
+)
+
+// point 是注册为值类型的参数
+type point struct {
+	X int `json:"x" msgpack:"x"`
+	Y int `json:"y" msgpack:"y"`
+}
+
+// label 是注册为指针类型的参数
+type label struct {
+	Text string `json:"text" msgpack:"text"`
+}
+
+func init() {
+	studio.MustRegister(`envelope_test.point`, point{})
+	studio.MustRegister(`envelope_test.label`, (*label)(nil))
+	studio.MustRegister(`envelope_test.point_ptr`, (*point)(nil)) // 值类型与指针类型是不同的类型
+}
+
+func TestEnvelopeParamType(t *testing.T) {
+	for _, ct := range []codec.Type{codec.Json, codec.MsgP} {
+		b, err := studio.Encode(ct, studio.NewEvent(`value`, point{X: 1, Y: 2}, nil))
+		if err != nil {
+			t.Fatal(err)
+		}
+		e, err := studio.Decode(ct, b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p, ok := e.Param().(point); !ok || p != (point{X: 1, Y: 2}) {
+			t.Errorf("codec %d: value param %#v, want point{1 2}", ct, e.Param())
+		}
+
+		if b, err = studio.Encode(ct, studio.NewEvent(`pointer`, &label{Text: `hi`}, nil)); err != nil {
+			t.Fatal(err)
+		}
+		if e, err = studio.Decode(ct, b); err != nil {
+			t.Fatal(err)
+		}
+		if l, ok := e.Param().(*label); !ok || l == nil || l.Text != `hi` {
+			t.Errorf("codec %d: pointer param %#v, want &label{hi}", ct, e.Param())
+		}
+	}
+}
+
+func TestRegisterDuplicate(t *testing.T) {
+	if err := studio.Register(`envelope_test.point`, struct{}{}); !errors.Is(err, studio.ErrTypeRegistered) {
+		t.Errorf("duplicate name error %v, want %v", err, studio.ErrTypeRegistered)
+	}
+	if err := studio.Register(`envelope_test.other`, point{}); !errors.Is(err, studio.ErrTypeRegistered) {
+		t.Errorf("duplicate type error %v, want %v", err, studio.ErrTypeRegistered)
+	}
+}
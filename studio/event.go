@@ -2,15 +2,18 @@ package studio
 
 import (
 	"github.com/azeroth-sha/simple"
+	"github.com/azeroth-sha/simple/guid"
 	"time"
 )
 
 // message 是 Event 接口的具体实现，表示一个事件消息。
 type message struct {
-	t time.Time  // 事件发生的时间
-	n string     // 事件的名称
-	d any        // 事件的主要参数
-	a simple.Map // 事件的附加参数
+	t time.Time       // 事件发生的时间
+	n string          // 事件的名称
+	d any             // 事件的主要参数
+	a simple.Map      // 事件的附加参数
+	i guid.GUID       // 事件的唯一标识（仅由信封还原的事件携带）
+	h simple.MSString // 事件的头信息（仅由信封还原的事件携带）
 }
 
 // Occurred 返回事件发生的时间。
@@ -37,6 +40,18 @@ func (m *message) Additive() simple.Map {
 	return m.a
 }
 
+// ID 返回事件的唯一标识。
+// 返回值为 guid.GUID 类型，未设置时为 guid.NULL。
+func (m *message) ID() guid.GUID {
+	return m.i
+}
+
+// Headers 返回事件的头信息。
+// 返回值为 simple.MSString 类型，通常用于传递跨进程的元数据。
+func (m *message) Headers() simple.MSString {
+	return m.h
+}
+
 // NewEvent 创建一个新的事件实例。
 // 参数 n 是事件的名称。
 // 参数 d 是事件的主要参数。
@@ -1,5 +1,11 @@
 package studio
 
+import (
+	"github.com/azeroth-sha/simple"
+	"github.com/azeroth-sha/simple/codec"
+	"time"
+)
+
 // Option 是一个函数类型，用于配置 engine 实例
 type Option func(*engine)
 
@@ -38,3 +44,81 @@ func WithPanicFunc(h func()) Option {
 		e.panicFunc = h
 	}
 }
+
+// WithReplyPolicy 设置 Call 与 Async 的默认应答策略
+// 默认为 ReplyFirst
+func WithReplyPolicy(p ReplyPolicy) Option {
+	return func(e *engine) {
+		e.policy = p
+	}
+}
+
+// WithDrainTimeout 设置释放时处理剩余事件的期限
+// 参数 d 大于 0 时，Release 先停止接收新事件并在期限内处理队列中的剩余事件
+// 默认为 0，即 Release 立即停止并丢弃剩余事件
+func WithDrainTimeout(d time.Duration) Option {
+	return func(e *engine) {
+		if d > 0 {
+			e.drain = d
+		}
+	}
+}
+
+// BridgeOption 是一个函数类型，用于配置 Bridge 实例
+type BridgeOption func(*Bridge)
+
+// WithBridgeCodec 设置信封的编码类型
+// 默认为 codec.MsgP，所有节点需使用相同的编码类型
+func WithBridgeCodec(t codec.Type) BridgeOption {
+	return func(b *Bridge) {
+		b.codecType = t
+	}
+}
+
+// WithBridgeQueue 设置每个远端的发送队列容量
+// 参数 n 必须大于 0，否则配置无效
+func WithBridgeQueue(n int) BridgeOption {
+	return func(b *Bridge) {
+		if n > 0 {
+			b.queueSize = n
+		}
+	}
+}
+
+// WithBridgeWindow 设置每个远端允许的未确认事件数量
+// 参数 n 必须大于 0，否则配置无效
+func WithBridgeWindow(n int) BridgeOption {
+	return func(b *Bridge) {
+		if n > 0 {
+			b.window = n
+		}
+	}
+}
+
+// WithBridgeRetry 设置断线重连的间隔
+// 参数 d 必须大于 0，否则配置无效
+func WithBridgeRetry(d time.Duration) BridgeOption {
+	return func(b *Bridge) {
+		if d > 0 {
+			b.retry = d
+		}
+	}
+}
+
+// WithBridgeTimeout 设置连接远端的超时时间
+// 参数 d 必须大于 0，否则配置无效
+func WithBridgeTimeout(d time.Duration) BridgeOption {
+	return func(b *Bridge) {
+		if d > 0 {
+			b.timeout = d
+		}
+	}
+}
+
+// WithBridgeLogger 设置日志记录器
+// 未设置时不记录连接与编码错误
+func WithBridgeLogger(l simple.Logger) BridgeOption {
+	return func(b *Bridge) {
+		b.logger = l
+	}
+}
@@ -1,7 +1,9 @@
 package studio
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"runtime"
 	"sync"
 	"sync/atomic"
@@ -12,30 +14,76 @@ import (
 var (
 	ErrReleased = errors.New(`studio released`)     // 引擎已释放时返回的错误
 	ErrFull     = errors.New(`event queue is full`) // 事件队列满时返回的错误
+	ErrDropped  = errors.New(`events dropped`)      // 释放时存在未处理事件返回的错误
 )
 
+// ReleaseError 表示释放引擎时未被处理的事件报告
+type ReleaseError struct {
+	Events []Event // 未被处理的事件，按入队顺序排列
+}
+
+// Error 实现 error 接口
+func (e *ReleaseError) Error() string {
+	return fmt.Sprintf("%s: %d unprocessed", ErrDropped, len(e.Events))
+}
+
+// Unwrap 返回 ErrDropped，便于使用 errors.Is 判断
+func (e *ReleaseError) Unwrap() error {
+	return ErrDropped
+}
+
 // engine 实现 Studio 接口的事件处理引擎
 type engine struct {
 	running   int32                // 原子操作标记引擎运行状态（1运行中/0已停止）
-	closed    chan struct{}        // 引擎关闭信号通道
+	closed    chan struct{}        // 引擎关闭信号通道（停止接收新事件）
+	quit      chan struct{}        // 工作线程退出信号通道
+	sendMu    *sync.RWMutex        // 保护事件入队与关闭事件管道的读写锁
+	drain     time.Duration        // 释放时处理剩余事件的期限
 	pipeSize  int                  // 事件队列管道容量
 	pipeline  chan Event           // 事件队列管道
 	jobSize   int                  // 工作线程数量
 	jobWait   *sync.WaitGroup      // 等待所有工作线程退出的同步器
 	recycler  Handler              // 未匹配事件处理器（回收处理器）
-	jobMu     *sync.RWMutex        // 保护 jobMap 和 recycler 的读写锁
+	jobMu     *sync.RWMutex        // 保护 jobMap、recycler 和 throttles 的读写锁
 	jobMap    map[string][]Handler // 事件名称到处理器的映射表
 	panicFunc func()               // 恐慌恢复函数
+	policy    ReplyPolicy          // 默认应答策略
+	batchers  []*batcher           // 批量工作站收集器列表
+	batchWait *sync.WaitGroup      // 等待所有批量处理器执行完毕的同步器
+	throttles map[string]Throttle  // 事件名称到节流策略的映射表
+	delayWait *sync.WaitGroup      // 等待所有被延迟的事件处理完毕的同步器
 }
 
 // Release 释放引擎资源，停止所有工作线程
-func (eng *engine) Release() {
-	if atomic.SwapInt32(&eng.running, 0) != 1 {
-		return
+// 配置了 WithDrainTimeout 时，先在期限内处理队列中的剩余事件
+// 存在未处理的事件时返回 *ReleaseError
+func (eng *engine) Release() error {
+	if eng.drain <= 0 {
+		return eng.release(context.Background(), false)
 	}
-	defer close(eng.pipeline) // 确保关闭事件管道
-	close(eng.closed)         // 发送关闭信号
-	eng.jobWait.Wait()        // 等待所有工作线程退出
+	ctx, cancel := context.WithTimeout(context.Background(), eng.drain)
+	defer cancel()
+	return eng.release(ctx, true)
+}
+
+// Drain 停止接收新事件，处理队列中的剩余事件直到 ctx 结束，然后释放引擎
+// 存在未处理的事件时返回 *ReleaseError
+func (eng *engine) Drain(ctx context.Context) error {
+	return eng.release(ctx, true)
+}
+
+// Start 阻塞直到引擎被释放，使工作室可作为 grace.Server 运行
+func (eng *engine) Start() error {
+	if !eng.isRunning() {
+		return ErrReleased
+	}
+	<-eng.closed
+	return nil
+}
+
+// Stop 释放引擎，等同于 Release
+func (eng *engine) Stop() error {
+	return eng.Release()
 }
 
 // Task 将事件加入处理队列，支持三种模式：
@@ -46,6 +94,11 @@ func (eng *engine) Task(e Event, block bool, exp ...time.Duration) error {
 	if !eng.isRunning() {
 		return ErrReleased
 	}
+	eng.sendMu.RLock()
+	defer eng.sendMu.RUnlock()
+	if !eng.isRunning() { // 持锁后再次检查，确保事件管道未关闭
+		return ErrReleased
+	}
 	if block {
 		select {
 		case eng.pipeline <- e:
@@ -119,6 +172,9 @@ func New(opts ...Option) Studio {
 	var obj = &engine{
 		running:   1,
 		closed:    make(chan struct{}),
+		quit:      make(chan struct{}),
+		sendMu:    new(sync.RWMutex),
+		drain:     0,
 		pipeSize:  numCPU,     // 默认管道容量=CPU核心数
 		pipeline:  nil,        // 事件队列管道
 		jobSize:   numCPU * 2, // 默认工作线程数=2*CPU核心数
@@ -127,6 +183,11 @@ func New(opts ...Option) Studio {
 		jobMu:     new(sync.RWMutex),
 		jobMap:    make(map[string][]Handler),
 		panicFunc: nil,
+		policy:    ReplyFirst,
+		batchers:  nil,
+		batchWait: new(sync.WaitGroup),
+		throttles: make(map[string]Throttle),
+		delayWait: new(sync.WaitGroup),
 	}
 	for _, opt := range opts {
 		opt(obj)
@@ -149,20 +210,36 @@ EXIT:
 		case e, ok := <-eng.pipeline:
 			if !ok {
 				break EXIT
-			} else if handles := eng.getHandles(e.Name()); len(handles) > 0 {
-				cnt := len(handles)
-				for i := 0; i < cnt; i++ {
-					wait.Add(1)
-					go eng.work(wait, handles[i], e)()
-				}
-				wait.Wait()
+			} else if t := eng.getThrottle(e.Name()); t != nil {
+				t.admit(eng, e) // 由节流策略决定放行、丢弃或延迟处理
+			} else {
+				eng.dispatch(wait, e)
 			}
-		case <-eng.closed:
+		case <-eng.quit:
 			break EXIT
 		}
 	}
 }
 
+// dispatch 将事件交给对应的处理器并等待执行完毕（内部方法）
+func (eng *engine) dispatch(wait *sync.WaitGroup, e Event) {
+	c, ok := e.(*call)
+	if ok {
+		c.hold() // 批量处理器会再次登记，批次执行完毕前不生成结果
+	}
+	if handles := eng.getHandles(e.Name()); len(handles) > 0 {
+		cnt := len(handles)
+		for i := 0; i < cnt; i++ {
+			wait.Add(1)
+			go eng.work(wait, handles[i], e)()
+		}
+		wait.Wait()
+	}
+	if ok {
+		c.release() // 所有处理器执行完毕，生成请求结果
+	}
+}
+
 // work 执行单个处理器任务（内部方法）
 func (eng *engine) work(w *sync.WaitGroup, h Handler, e Event) func() {
 	return func() {
@@ -188,6 +265,60 @@ func (eng *engine) getHandles(n string) []Handler {
 	return handles
 }
 
+// release 停止接收新事件并释放引擎，drain 为 true 时在 ctx 结束前处理剩余事件（内部方法）
+func (eng *engine) release(ctx context.Context, drain bool) error {
+	if atomic.SwapInt32(&eng.running, 0) != 1 {
+		return nil
+	}
+	close(eng.closed)   // 拒绝新事件并唤醒阻塞的发布者
+	eng.sendMu.Lock()   // 等待所有发布者退出
+	close(eng.pipeline) // 此后不再有事件入队
+	eng.sendMu.Unlock()
+	if drain {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			eng.jobWait.Wait() // 工作线程处理完剩余事件后自行退出
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+		}
+	}
+	close(eng.quit)          // 通知工作线程退出，正在执行的处理器不会被中断
+	eng.jobWait.Wait()       // 等待所有工作线程退出
+	dropped := eng.discard() // 收集队列中未处理的事件
+	eng.flushThrottle()      // 处理被节流策略延迟的事件
+	eng.flushBatch()         // 提交未满的批次
+	if len(dropped) > 0 {
+		return &ReleaseError{Events: dropped}
+	}
+	return nil
+}
+
+// discard 取出队列中剩余的事件，并以 ErrReleased 结束其中的请求（内部方法）
+func (eng *engine) discard() []Event {
+	var dropped []Event
+	for e := range eng.pipeline {
+		if c, ok := e.(*call); ok {
+			c.resolve(nil, ErrReleased)
+		}
+		dropped = append(dropped, Unwrap(e))
+	}
+	return dropped
+}
+
+// flushBatch 提交所有批量工作站中未满的批次并等待执行完毕（内部方法）
+func (eng *engine) flushBatch() {
+	eng.jobMu.RLock()
+	batchers := append([]*batcher(nil), eng.batchers...)
+	eng.jobMu.RUnlock()
+	for _, b := range batchers {
+		b.flush()
+	}
+	eng.batchWait.Wait()
+}
+
 // isRunning 检查引擎是否运行中（内部方法）
 func (eng *engine) isRunning() bool {
 	return atomic.LoadInt32(&eng.running) == 1
@@ -0,0 +1,62 @@
+package studio_test
+
+import (
+	"errors"
+	"github.com/azeroth-sha/simple/studio"
+	"testing"
+	"time"
+)
+
+func TestReleaseDrain(t *testing.T) {
+	s := studio.New(studio.WithJobSize(1), studio.WithPipeSize(8), studio.WithDrainTimeout(time.Second*5))
+	rec := new(recorder)
+	s.AddWorkstation(`slow`, func(e studio.Event) {
+		time.Sleep(time.Millisecond * 5)
+		rec.handle(e)
+	})
+	for i := 0; i < 8; i++ {
+		if err := s.Task(studio.NewEvent(`slow`, i, nil), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Release(); err != nil {
+		t.Fatalf("release: %v", err)
+	}
+	if got := rec.list(); len(got) != 8 {
+		t.Errorf("processed %v, want all 8 events", got)
+	}
+}
+
+func TestReleaseDrainTimeout(t *testing.T) {
+	s := studio.New(studio.WithJobSize(1), studio.WithPipeSize(8), studio.WithDrainTimeout(time.Millisecond*50))
+	rec := new(recorder)
+	started, gate := make(chan struct{}, 8), make(chan struct{})
+	s.AddWorkstation(`stuck`, func(e studio.Event) {
+		started <- struct{}{}
+		<-gate // 阻塞唯一的工作线程直到期限之后
+		rec.handle(e)
+	})
+	const total = 4
+	for i := 0; i < total; i++ {
+		if err := s.Task(studio.NewEvent(`stuck`, i, nil), true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-started
+	time.AfterFunc(time.Millisecond*200, func() { close(gate) })
+	err := s.Release()
+	var re *studio.ReleaseError
+	if !errors.As(err, &re) || !errors.Is(err, studio.ErrDropped) {
+		t.Fatalf("release error %v, want *ReleaseError wrapping %v", err, studio.ErrDropped)
+	}
+	processed := rec.list()
+	if len(re.Events) == 0 || len(processed)+len(re.Events) != total {
+		t.Fatalf("processed %v, dropped %d of %d events", processed, len(re.Events), total)
+	}
+	// 未处理的事件按入队顺序排在已处理的事件之后
+	for i, e := range re.Events {
+		if e.Name() != `stuck` || e.Param() != len(processed)+i {
+			t.Errorf("dropped event %d is %s %v, want stuck %d", i, e.Name(), e.Param(), len(processed)+i)
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package studio
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrThrottled 表示请求事件被节流策略丢弃或被后续事件取代
+var ErrThrottled = errors.New(`event throttled`)
+
+// KeyFunc 返回事件的节流键，同名且同键的事件共享去抖与合并窗口。
+type KeyFunc func(e Event) string
+
+// MergeFunc 合并两个事件，返回合并后的事件。
+// 参数 prev 是窗口内已合并的事件，参数 next 是新到达的事件。
+type MergeFunc func(prev, next Event) Event
+
+// Throttle 表示工作站的节流策略，由 RateLimit、Debounce 或 Coalesce 创建。
+type Throttle interface {
+	// admit 接收一个事件，决定立即处理、丢弃或延迟处理
+	admit(eng *engine, e Event)
+	// flush 立即处理所有被延迟的事件
+	flush(eng *engine)
+}
+
+// SetThrottle 设置指定名称工作站的节流策略（替换原有，nil 表示移除）
+func (eng *engine) SetThrottle(n string, t Throttle) {
+	if !eng.isRunning() {
+		return
+	}
+	eng.jobMu.Lock()
+	defer eng.jobMu.Unlock()
+	if t == nil {
+		delete(eng.throttles, n)
+	} else {
+		eng.throttles[n] = t
+	}
+}
+
+// RateLimit 创建令牌桶限流策略。
+// 参数 n 和 per 表示每 per 时长最多处理 n 个事件，参数 burst 是允许的突发数量。
+// 超出速率的事件被丢弃；n 或 per 小于等于 0 时 panic。
+func RateLimit(n int, per time.Duration, burst int) Throttle {
+	if n <= 0 || per <= 0 {
+		panic("studio: non-positive rate for RateLimit")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &rateLimit{
+		rate:   float64(n) / per.Seconds(),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Debounce 创建去抖策略。
+// 同名且同键的事件在最后一次到达后静默 window 时长才被处理，期间只保留最后一个事件。
+// 参数 key 可为空，此时同名事件共享一个窗口。
+func Debounce(window time.Duration, key KeyFunc) Throttle {
+	return &debounce{
+		window:  window,
+		key:     key,
+		pending: make(map[string]*delayed),
+	}
+}
+
+// Coalesce 创建合并策略。
+// 同名且同键的事件自第一个事件到达起 window 时长内使用 merge 合并，窗口结束时处理合并后的事件。
+// 参数 key 可为空，此时同名事件共享一个窗口；参数 merge 为空时保留最后一个事件。
+func Coalesce(window time.Duration, key KeyFunc, merge MergeFunc) Throttle {
+	return &coalesce{
+		window:  window,
+		key:     key,
+		merge:   merge,
+		pending: make(map[string]*delayed),
+	}
+}
+
+/*
+  内部方法
+*/
+
+// getThrottle 获取事件对应的节流策略（内部方法）
+func (eng *engine) getThrottle(n string) Throttle {
+	eng.jobMu.RLock()
+	defer eng.jobMu.RUnlock()
+	return eng.throttles[n]
+}
+
+// flushThrottle 处理所有被延迟的事件并等待执行完毕（内部方法）
+func (eng *engine) flushThrottle() {
+	eng.jobMu.RLock()
+	throttles := make([]Throttle, 0, len(eng.throttles))
+	for _, t := range eng.throttles {
+		throttles = append(throttles, t)
+	}
+	eng.jobMu.RUnlock()
+	for _, t := range throttles {
+		t.flush(eng)
+	}
+	eng.delayWait.Wait()
+}
+
+// reject 以 ErrThrottled 结束被丢弃的请求（内部方法）
+func reject(e Event) {
+	if c, ok := e.(*call); ok {
+		c.resolve(nil, ErrThrottled)
+	}
+}
+
+// rateLimit 令牌桶限流策略
+type rateLimit struct {
+	mu     sync.Mutex
+	rate   float64   // 每秒补充的令牌数量
+	burst  float64   // 令牌桶容量
+	tokens float64   // 当前令牌数量
+	last   time.Time // 上次补充令牌的时间
+}
+
+// admit 有令牌时立即处理事件，否则丢弃（内部方法）
+func (r *rateLimit) admit(eng *engine, e Event) {
+	if r.take() {
+		eng.dispatch(new(sync.WaitGroup), e)
+	} else {
+		reject(e)
+	}
+}
+
+// flush 限流策略不延迟事件（内部方法）
+func (r *rateLimit) flush(*engine) {}
+
+// take 补充并获取一个令牌（内部方法）
+func (r *rateLimit) take() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	r.tokens += now.Sub(r.last).Seconds() * r.rate
+	r.last = now
+	if r.tokens > r.burst {
+		r.tokens = r.burst
+	}
+	if r.tokens < 1 {
+		return false
+	}
+	r.tokens--
+	return true
+}
+
+// delayed 表示一个等待窗口结束的事件
+type delayed struct {
+	e     Event       // 待处理的事件
+	timer *time.Timer // 窗口定时器
+}
+
+// debounce 去抖策略
+type debounce struct {
+	mu      sync.Mutex
+	window  time.Duration       // 静默窗口时长
+	key     KeyFunc             // 节流键函数
+	pending map[string]*delayed // 等待窗口结束的事件
+}
+
+// admit 保留最后一个事件并重置窗口（内部方法）
+func (d *debounce) admit(eng *engine, e Event) {
+	k := throttleKey(d.key, e)
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if p, ok := d.pending[k]; ok {
+		reject(p.e)
+		p.e = e
+		if p.timer.Stop() {
+			p.timer.Reset(d.window)
+		}
+		return
+	}
+	p := &delayed{e: e}
+	d.pending[k] = p
+	eng.delayWait.Add(1)
+	p.timer = time.AfterFunc(d.window, func() {
+		fire(eng, &d.mu, d.pending, k, p)
+	})
+}
+
+// flush 立即处理所有等待中的事件（内部方法）
+func (d *debounce) flush(eng *engine) {
+	flushDelayed(eng, &d.mu, d.pending)
+}
+
+// coalesce 合并策略
+type coalesce struct {
+	mu      sync.Mutex
+	window  time.Duration       // 合并窗口时长
+	key     KeyFunc             // 节流键函数
+	merge   MergeFunc           // 合并函数
+	pending map[string]*delayed // 等待窗口结束的事件
+}
+
+// admit 将事件合并到当前窗口（内部方法）
+func (c *coalesce) admit(eng *engine, e Event) {
+	k := throttleKey(c.key, e)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if p, ok := c.pending[k]; ok {
+		reject(p.e)
+		if c.merge != nil {
+			reject(e)
+			e = c.merge(Unwrap(p.e), Unwrap(e))
+		}
+		p.e = e
+		return
+	}
+	p := &delayed{e: e}
+	c.pending[k] = p
+	eng.delayWait.Add(1)
+	p.timer = time.AfterFunc(c.window, func() {
+		fire(eng, &c.mu, c.pending, k, p)
+	})
+}
+
+// flush 立即处理所有等待中的事件（内部方法）
+func (c *coalesce) flush(eng *engine) {
+	flushDelayed(eng, &c.mu, c.pending)
+}
+
+// fire 窗口结束时处理等待中的事件（内部方法）
+func fire(eng *engine, mu *sync.Mutex, pending map[string]*delayed, k string, p *delayed) {
+	defer eng.delayWait.Done()
+	mu.Lock()
+	if pending[k] == p {
+		delete(pending, k)
+	}
+	e := p.e
+	mu.Unlock()
+	eng.dispatch(new(sync.WaitGroup), e)
+}
+
+// flushDelayed 停止所有窗口定时器并立即处理等待中的事件（内部方法）
+func flushDelayed(eng *engine, mu *sync.Mutex, pending map[string]*delayed) {
+	mu.Lock()
+	all := make([]Event, 0, len(pending))
+	for k, p := range pending {
+		if p.timer.Stop() { // 定时器已触发的事件由 fire 处理
+			all = append(all, p.e)
+			delete(pending, k)
+		}
+	}
+	mu.Unlock()
+	for _, e := range all {
+		eng.dispatch(new(sync.WaitGroup), e)
+		eng.delayWait.Done()
+	}
+}
+
+// throttleKey 计算事件的节流键（内部方法）
+func throttleKey(key KeyFunc, e Event) string {
+	if key == nil {
+		return e.Name()
+	}
+	return e.Name() + "\x00" + key(Unwrap(e))
+}
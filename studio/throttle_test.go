@@ -0,0 +1,96 @@
+package studio_test
+
+import (
+	"context"
+	"github.com/azeroth-sha/simple/studio"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recorder 记录处理器收到的事件参数
+type recorder struct {
+	mu     sync.Mutex
+	params []any
+}
+
+func (r *recorder) handle(e studio.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.params = append(r.params, e.Param())
+}
+
+func (r *recorder) list() []any {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]any(nil), r.params...)
+}
+
+func TestRateLimit(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	s.AddWorkstation(`rate`, func(e studio.Event) { studio.Reply(e, e.Param(), nil) })
+	s.SetThrottle(`rate`, studio.RateLimit(1, time.Millisecond*100, 1))
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if _, err := s.Call(ctx, studio.NewEvent(`rate`, 1, nil)); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	if _, err := s.Call(ctx, studio.NewEvent(`rate`, 2, nil)); err != studio.ErrThrottled {
+		t.Fatalf("second call error %v, want %v", err, studio.ErrThrottled)
+	}
+	time.Sleep(time.Millisecond * 150) // 等待补充一个令牌
+	if _, err := s.Call(ctx, studio.NewEvent(`rate`, 3, nil)); err != nil {
+		t.Errorf("call after refill: %v", err)
+	}
+}
+
+func TestRateLimitInvalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RateLimit(0) did not panic")
+		}
+	}()
+	studio.RateLimit(0, time.Second, 1)
+}
+
+func TestDebounce(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	rec := new(recorder)
+	s.AddWorkstation(`debounce`, rec.handle)
+	s.SetThrottle(`debounce`, studio.Debounce(time.Millisecond*100, nil))
+	for i := 1; i <= 5; i++ {
+		_ = s.Task(studio.NewEvent(`debounce`, i, nil), true)
+		time.Sleep(time.Millisecond * 20)
+	}
+	time.Sleep(time.Millisecond * 40) // 距最后一个事件不足一个窗口
+	if got := rec.list(); len(got) != 0 {
+		t.Fatalf("handled %v before the window ended", got)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if got := rec.list(); len(got) != 1 || got[0] != 5 {
+		t.Errorf("handled %v, want [5]", got)
+	}
+}
+
+func TestCoalesce(t *testing.T) {
+	s := studio.New()
+	defer func() { _ = s.Release() }()
+	rec := new(recorder)
+	s.AddWorkstation(`coalesce`, rec.handle)
+	s.SetThrottle(`coalesce`, studio.Coalesce(time.Millisecond*100, nil, func(prev, next studio.Event) studio.Event {
+		return studio.NewEvent(next.Name(), prev.Param().(int)+next.Param().(int), nil)
+	}))
+	for i := 1; i <= 4; i++ {
+		_ = s.Task(studio.NewEvent(`coalesce`, i, nil), true)
+	}
+	time.Sleep(time.Millisecond * 50)
+	if got := rec.list(); len(got) != 0 {
+		t.Fatalf("handled %v before the window ended", got)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if got := rec.list(); len(got) != 1 || got[0] != 10 {
+		t.Errorf("handled %v, want [10]", got)
+	}
+}